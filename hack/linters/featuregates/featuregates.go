@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package featuregates defines an analyzer that checks that every exported
+// featuregate.Feature constant carries a doc comment declaring its owner and
+// stability, per the feature gate documentation policy.
+package featuregates
+
+import (
+	"fmt"
+	"go/ast"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const featureTypeName = "k8s.io/component-base/featuregate.Feature"
+
+// defaultMarkerPattern matches the conventional feature gate doc comment markers,
+// e.g. "owner: @someone" or "beta: v1.31".
+const defaultMarkerPattern = `(?i)(owner:|alpha:|beta:|stable:|deprecated:)`
+
+var markerPattern string
+
+// suggestBlameAuthor, when set, augments missing-doc reports with an owner suggestion
+// looked up from git blame. Off by default so the analyzer works without git.
+var suggestBlameAuthor bool
+
+// blameAuthor looks up the author of the line. Overridable in tests; the default runs
+// git blame and returns the empty string when git is unavailable.
+var blameAuthor = gitBlameAuthor
+
+var Analyzer = &analysis.Analyzer{
+	Name: "featuregatedoc",
+	Doc:  "checks that exported featuregate.Feature constants have a doc comment with an owner/stability marker",
+	Run:  run,
+}
+
+func init() {
+	Analyzer.Flags.StringVar(&markerPattern, "marker", defaultMarkerPattern,
+		"regular expression a feature gate doc comment must match")
+	Analyzer.Flags.BoolVar(&suggestBlameAuthor, "blame", false,
+		"suggest an owner from git blame in missing-doc reports; requires git")
+}
+
+// gitBlameAuthor returns the last author of the line per git blame, or the empty string
+// when the lookup fails (e.g. git is unavailable or the file is not tracked).
+func gitBlameAuthor(filename string, line int) string {
+	out, err := exec.Command("git", "blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", line, line), "--", filename).Output()
+	if err != nil {
+		return ""
+	}
+	for _, outputLine := range strings.Split(string(out), "\n") {
+		if author, ok := strings.CutPrefix(outputLine, "author "); ok {
+			return author
+		}
+	}
+	return ""
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	marker, err := regexp.Compile(markerPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				checkValueSpec(pass, genDecl, valueSpec, marker)
+			}
+		}
+	}
+	return nil, nil
+}
+
+func checkValueSpec(pass *analysis.Pass, decl *ast.GenDecl, spec *ast.ValueSpec, marker *regexp.Regexp) {
+	for _, name := range spec.Names {
+		if !name.IsExported() {
+			continue
+		}
+		obj := pass.TypesInfo.Defs[name]
+		if obj == nil || obj.Type().String() != featureTypeName {
+			continue
+		}
+
+		doc := spec.Doc
+		// a single constant in a declaration carries the doc comment of the declaration.
+		if doc == nil && len(decl.Specs) == 1 {
+			doc = decl.Doc
+		}
+		if doc == nil {
+			message := fmt.Sprintf("feature gate %s is missing a doc comment with an owner/stability marker", name.Name)
+			if suggestBlameAuthor {
+				position := pass.Fset.Position(name.Pos())
+				if author := blameAuthor(position.Filename, position.Line); author != "" {
+					message = fmt.Sprintf("%s, consider \"// owner: %s\"", message, author)
+				}
+			}
+			pass.Reportf(name.Pos(), "%s", message)
+			continue
+		}
+		if !marker.MatchString(doc.Text()) {
+			pass.Reportf(name.Pos(), "doc comment of feature gate %s lacks an owner/stability marker matching %q", name.Name, marker.String())
+		}
+	}
+}
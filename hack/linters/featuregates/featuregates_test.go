@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package featuregates
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "example")
+}
+
+func TestBlameSuggestion(t *testing.T) {
+	originalBlameAuthor, originalSuggest := blameAuthor, suggestBlameAuthor
+	defer func() { blameAuthor, suggestBlameAuthor = originalBlameAuthor, originalSuggest }()
+	suggestBlameAuthor = true
+	blameAuthor = func(filename string, line int) string { return "jdoe" }
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "blame")
+}
+
+func TestBlameSuggestionWithoutGit(t *testing.T) {
+	originalBlameAuthor, originalSuggest := blameAuthor, suggestBlameAuthor
+	defer func() { blameAuthor, suggestBlameAuthor = originalBlameAuthor, originalSuggest }()
+	suggestBlameAuthor = true
+	// a failing blame lookup falls back to the generic report, which the
+	// example package already asserts.
+	blameAuthor = func(filename string, line int) string { return "" }
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "example")
+}
@@ -0,0 +1,12 @@
+module k8s.io/kubernetes/hack/linters/featuregates
+
+go 1.23.0
+
+godebug default=go1.23
+
+require golang.org/x/tools v0.21.1-0.20240531212143-b6235391adb3
+
+require (
+	golang.org/x/mod v0.20.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+)
@@ -0,0 +1,19 @@
+package example
+
+import "k8s.io/component-base/featuregate"
+
+// owner: @documented
+// beta: v1.31
+//
+// Enables the documented example feature.
+const DocumentedFeature featuregate.Feature = "DocumentedFeature"
+
+const UndocumentedFeature featuregate.Feature = "UndocumentedFeature" // want `feature gate UndocumentedFeature is missing a doc comment with an owner/stability marker`
+
+// Enables another example feature, but forgets the markers.
+const UnmarkedFeature featuregate.Feature = "UnmarkedFeature" // want `doc comment of feature gate UnmarkedFeature lacks an owner/stability marker`
+
+// unexported constants and constants of other types are ignored.
+const internalFeature featuregate.Feature = "internalFeature"
+
+const NotAFeature = "NotAFeature"
@@ -0,0 +1,5 @@
+// Package featuregate is a minimal stub of k8s.io/component-base/featuregate for the analyzer tests.
+package featuregate
+
+// Feature indicates a feature name.
+type Feature string
@@ -35,3 +35,11 @@ type Info struct {
 func (info Info) String() string {
 	return info.GitVersion
 }
+
+// EffectiveInfo contains versioning information about a binary together with the
+// versions it effectively emulates and retains compatibility with.
+type EffectiveInfo struct {
+	Info                    `json:",inline"`
+	EmulationVersion        string `json:"emulationVersion,omitempty"`
+	MinCompatibilityVersion string `json:"minCompatibilityVersion,omitempty"`
+}
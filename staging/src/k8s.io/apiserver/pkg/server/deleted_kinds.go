@@ -19,6 +19,7 @@ package server
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -28,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	apimachineryversion "k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/apiserver/pkg/registry/rest"
+	utilversion "k8s.io/component-base/version"
 	"k8s.io/klog/v2"
 )
 
@@ -42,6 +44,45 @@ type resourceExpirationEvaluator struct {
 	// This should be made a flag before merge
 	// Set KUBE_APISERVER_SERVE_REMOVED_APIS_FOR_ONE_RELEASE to prevent removing APIs for one more release.
 	serveRemovedAPIsOneMoreRelease bool
+	// This is set for strict deployments that require every served resource to declare an introduced version.
+	// Set KUBE_APISERVER_REQUIRE_INTRODUCED_VERSION to collect resources missing APILifecycleIntroduced
+	// in MissingLifecycleResources for a startup error or warning.
+	requireIntroducedVersion bool
+	// missingLifecycleResources collects the resources seen by RemoveDeletedKinds that lack an introduced version.
+	// It is only populated when requireIntroducedVersion is set.
+	missingLifecycleResources []schema.GroupVersionResource
+	// invalidLifecycleResources collects the resources seen by RemoveDeletedKinds whose introduced
+	// version is after their removed version, which is a bug in the resource definition.
+	invalidLifecycleResources []schema.GroupVersionResource
+	// retainUnexpiredSubresources, when set, keeps serving a subresource with its own later
+	// removal version when its parent resource is removed, instead of removing them together.
+	// Set KUBE_APISERVER_RETAIN_UNEXPIRED_SUBRESOURCES to preserve such subresources.
+	retainUnexpiredSubresources bool
+	// minCompatibilityVersion, if set together with serveRemovedReadOnlyToMinCompatibility,
+	// keeps a removed API servable read-only as long as the min compatibility version is at
+	// most its removal version, for storage read-compatibility with older releases.
+	minCompatibilityVersion *apimachineryversion.Version
+	// serveRemovedReadOnlyToMinCompatibility enables the read-only retention described on
+	// minCompatibilityVersion.
+	serveRemovedReadOnlyToMinCompatibility bool
+	// removedAPIAllowedVerbs, when non-empty, keeps removed resources served with only the
+	// listed verbs instead of removing them; unlisted verbs answer Method Not Allowed.
+	// Set KUBE_APISERVER_REMOVED_API_ALLOWED_VERBS to a comma-separated verb list, e.g.
+	// "get,list,delete" to let controllers clean up existing objects while blocking writes.
+	removedAPIAllowedVerbs sets.String
+	// emulationForwardCompatible, when set, keeps serving resources introduced after the current
+	// version instead of pruning them, so a lower emulation version can still serve newer APIs.
+	emulationForwardCompatible bool
+	// forwardCompatibleCeiling, if set, caps how far forward emulationForwardCompatible reaches:
+	// resources introduced after the ceiling are still pruned.
+	forwardCompatibleCeiling *apimachineryversion.Version
+	// removalGraceReleases, if set, returns how many extra releases past its removal version a
+	// resource keeps being served, so removal policy can vary per API. A nil callback falls
+	// back to the global serve-removed-APIs-for-one-release setting.
+	removalGraceReleases func(gvr schema.GroupVersionResource) int
+	// klogVerbosity is the verbosity the removal and retention logs are emitted at, so noisy
+	// environments can raise the threshold.
+	klogVerbosity klog.Level
 }
 
 // ResourceExpirationEvaluator indicates whether or not a resource should be served.
@@ -49,8 +90,34 @@ type ResourceExpirationEvaluator interface {
 	// RemoveDeletedKinds inspects the storage map and modifies it in place by removing storage for kinds that have been deleted.
 	// versionedResourcesStorageMap mirrors the field on APIGroupInfo, it's a map from version to resource to the storage.
 	RemoveDeletedKinds(groupName string, versioner runtime.ObjectVersioner, versionedResourcesStorageMap map[string]map[string]rest.Storage)
+	// FilterDeletedKinds returns a new storage map with storage for kinds that have been deleted removed,
+	// without mutating the input or destroying any storage.
+	FilterDeletedKinds(groupName string, versioner runtime.ObjectVersioner, versionedResourcesStorageMap map[string]map[string]rest.Storage) map[string]map[string]rest.Storage
+	// RemoveDeletedKindsForGroups removes deleted kinds like RemoveDeletedKinds for every group in
+	// the map, keyed by group name, and returns the group/version/resources removed, sorted.
+	RemoveDeletedKindsForGroups(versioner runtime.ObjectVersioner, versionedResourcesStorageMapByGroup map[string]map[string]map[string]rest.Storage) []schema.GroupVersionResource
 	// ShouldServeForVersion returns true if a particular version cut off is after the current version
 	ShouldServeForVersion(majorRemoved, minorRemoved int) bool
+	// MissingLifecycleResources returns the resources seen by RemoveDeletedKinds that do not declare an
+	// introduced version. It is only populated when the evaluator requires introduced versions.
+	MissingLifecycleResources() []schema.GroupVersionResource
+	// InvalidLifecycleResources returns the resources seen by RemoveDeletedKinds whose introduced
+	// version is after their removed version. Such a resource is never servable, so the
+	// inconsistency should fail startup loudly instead of mysteriously not serving.
+	InvalidLifecycleResources() []schema.GroupVersionResource
+	// SetLogVerbosity sets the verbosity the removal and retention logs are emitted at.
+	SetLogVerbosity(level klog.Level)
+	// ResourceLifecycles returns, for each resource in the storage map that would be served, the
+	// introduced and (if declared) scheduled removal version, so discovery can advertise them to
+	// clients planning migrations. Versions a resource does not declare are left nil.
+	ResourceLifecycles(groupName string, versioner runtime.ObjectVersioner, versionedResourcesStorageMap map[string]map[string]rest.Storage) map[schema.GroupVersionResource]APILifecycle
+}
+
+// APILifecycle holds the declared lifecycle versions of a served resource.
+// A nil version means the resource does not declare it.
+type APILifecycle struct {
+	Introduced *apimachineryversion.Version
+	Removed    *apimachineryversion.Version
 }
 
 func NewResourceExpirationEvaluator(currentVersion *apimachineryversion.Version) (ResourceExpirationEvaluator, error) {
@@ -60,6 +127,7 @@ func NewResourceExpirationEvaluator(currentVersion *apimachineryversion.Version)
 	klog.V(1).Infof("NewResourceExpirationEvaluator with currentVersion: %s.", currentVersion)
 	ret := &resourceExpirationEvaluator{
 		strictRemovedHandlingInAlpha: false,
+		klogVerbosity:                1,
 	}
 	// Only keeps the major and minor versions from input version.
 	ret.currentVersion = apimachineryversion.MajorMinor(currentVersion.Major(), currentVersion.Minor())
@@ -81,22 +149,100 @@ func NewResourceExpirationEvaluator(currentVersion *apimachineryversion.Version)
 		ret.serveRemovedAPIsOneMoreRelease = envBool
 	}
 
+	if envString, ok := os.LookupEnv("KUBE_APISERVER_REQUIRE_INTRODUCED_VERSION"); !ok {
+		// do nothing
+	} else if envBool, err := strconv.ParseBool(envString); err != nil {
+		return nil, err
+	} else {
+		ret.requireIntroducedVersion = envBool
+	}
+
+	if envString, ok := os.LookupEnv("KUBE_APISERVER_RETAIN_UNEXPIRED_SUBRESOURCES"); !ok {
+		// do nothing
+	} else if envBool, err := strconv.ParseBool(envString); err != nil {
+		return nil, err
+	} else {
+		ret.retainUnexpiredSubresources = envBool
+	}
+
+	if envString, ok := os.LookupEnv("KUBE_APISERVER_REMOVED_API_ALLOWED_VERBS"); ok && len(envString) > 0 {
+		ret.removedAPIAllowedVerbs = sets.NewString(strings.Split(envString, ",")...)
+	}
+
 	return ret, nil
 }
 
+// NewResourceExpirationEvaluatorForwardCompatible returns an evaluator that keeps serving
+// resources introduced after the current version, up to the optional ceiling. A nil ceiling
+// allows any future version; with a ceiling, resources introduced after it are still pruned.
+func NewResourceExpirationEvaluatorForwardCompatible(currentVersion, ceiling *apimachineryversion.Version) (ResourceExpirationEvaluator, error) {
+	ret, err := NewResourceExpirationEvaluator(currentVersion)
+	if err != nil {
+		return nil, err
+	}
+	evaluator := ret.(*resourceExpirationEvaluator)
+	evaluator.emulationForwardCompatible = true
+	evaluator.forwardCompatibleCeiling = ceiling
+	return evaluator, nil
+}
+
+// NewResourceExpirationEvaluatorReadOnlyToMinCompatibility returns an evaluator that keeps
+// a removed API servable read-only as long as the min compatibility version is at most its
+// removal version, even when the current version has moved past the removal.
+func NewResourceExpirationEvaluatorReadOnlyToMinCompatibility(currentVersion, minCompatibilityVersion *apimachineryversion.Version) (ResourceExpirationEvaluator, error) {
+	ret, err := NewResourceExpirationEvaluator(currentVersion)
+	if err != nil {
+		return nil, err
+	}
+	if minCompatibilityVersion == nil {
+		return nil, fmt.Errorf("empty NewResourceExpirationEvaluatorReadOnlyToMinCompatibility minCompatibilityVersion")
+	}
+	evaluator := ret.(*resourceExpirationEvaluator)
+	evaluator.minCompatibilityVersion = apimachineryversion.MajorMinor(minCompatibilityVersion.Major(), minCompatibilityVersion.Minor())
+	evaluator.serveRemovedReadOnlyToMinCompatibility = true
+	return evaluator, nil
+}
+
+// NewResourceExpirationEvaluatorWithRemovalGrace returns an evaluator that defers the removal
+// of each resource by the number of releases the callback returns for it, so deprecation
+// windows can vary per API instead of using the single global setting.
+func NewResourceExpirationEvaluatorWithRemovalGrace(currentVersion *apimachineryversion.Version, removalGraceReleases func(gvr schema.GroupVersionResource) int) (ResourceExpirationEvaluator, error) {
+	ret, err := NewResourceExpirationEvaluator(currentVersion)
+	if err != nil {
+		return nil, err
+	}
+	evaluator := ret.(*resourceExpirationEvaluator)
+	evaluator.removalGraceReleases = removalGraceReleases
+	return evaluator, nil
+}
+
 func (e *resourceExpirationEvaluator) shouldServe(gv schema.GroupVersion, versioner runtime.ObjectVersioner, resourceServingInfo rest.Storage) bool {
+	serve, _ := e.shouldServeWithReadOnly(gv.WithResource(""), versioner, resourceServingInfo)
+	return serve
+}
+
+// versionedExampleFor returns an example object for the storage converted to the group/version
+// it is served under, honoring storage that overrides its group version (used for things like
+// scale subresources). The versioned object carries the APILifecycle declarations the evaluator
+// probes for.
+func versionedExampleFor(gv schema.GroupVersion, versioner runtime.ObjectVersioner, resourceServingInfo rest.Storage) (runtime.Object, error) {
 	internalPtr := resourceServingInfo.New()
 
 	target := gv
-	// honor storage that overrides group version (used for things like scale subresources)
 	if versionProvider, ok := resourceServingInfo.(rest.GroupVersionKindProvider); ok {
 		target = versionProvider.GroupVersionKind(target).GroupVersion()
 	}
+	return versioner.ConvertToVersion(internalPtr, target)
+}
 
-	versionedPtr, err := versioner.ConvertToVersion(internalPtr, target)
+// shouldServeWithReadOnly is like shouldServe, but additionally reports whether the resource
+// is kept only for read access down to the min compatibility version. The resource of the
+// given gvr may be empty when the caller only knows the group/version.
+func (e *resourceExpirationEvaluator) shouldServeWithReadOnly(gvr schema.GroupVersionResource, versioner runtime.ObjectVersioner, resourceServingInfo rest.Storage) (bool, bool) {
+	versionedPtr, err := versionedExampleFor(gvr.GroupVersion(), versioner, resourceServingInfo)
 	if err != nil {
 		utilruntime.HandleError(err)
-		return false
+		return false, false
 	}
 
 	introduced, ok := versionedPtr.(introducedInterface)
@@ -104,15 +250,216 @@ func (e *resourceExpirationEvaluator) shouldServe(gv schema.GroupVersion, versio
 		majorIntroduced, minorIntroduced := introduced.APILifecycleIntroduced()
 		verIntroduced := apimachineryversion.MajorMinor(uint(majorIntroduced), uint(minorIntroduced))
 		if e.currentVersion.LessThan(verIntroduced) {
-			return false
+			if !e.emulationForwardCompatible {
+				return false, false
+			}
+			// forward compatibility only reaches up to the ceiling, if one is set.
+			if e.forwardCompatibleCeiling != nil && verIntroduced.GreaterThan(e.forwardCompatibleCeiling) {
+				return false, false
+			}
 		}
 	}
 
 	removed, ok := versionedPtr.(removedInterface)
 	if !ok {
-		return true
+		return true, false
 	}
 	majorRemoved, minorRemoved := removed.APILifecycleRemoved()
+	if e.shouldServeForVersionWithResource(gvr, majorRemoved, minorRemoved) {
+		return true, false
+	}
+	// a removed API stays readable down to the min compatibility version, so that storage
+	// written by a release that still served it can be read back.
+	if e.serveRemovedReadOnlyToMinCompatibility {
+		verRemoved := apimachineryversion.MajorMinor(uint(majorRemoved), uint(minorRemoved))
+		if !e.minCompatibilityVersion.GreaterThan(verRemoved) {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// hasIntroducedVersion returns true if the versioned object for the storage declares an introduced version.
+func (e *resourceExpirationEvaluator) hasIntroducedVersion(gv schema.GroupVersion, versioner runtime.ObjectVersioner, resourceServingInfo rest.Storage) bool {
+	versionedPtr, err := versionedExampleFor(gv, versioner, resourceServingInfo)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return false
+	}
+	_, ok := versionedPtr.(introducedInterface)
+	return ok
+}
+
+// hasInvalidLifecycle returns true if the versioned object for the storage declares an
+// introduced version after its removed version.
+func (e *resourceExpirationEvaluator) hasInvalidLifecycle(gv schema.GroupVersion, versioner runtime.ObjectVersioner, resourceServingInfo rest.Storage) bool {
+	versionedPtr, err := versionedExampleFor(gv, versioner, resourceServingInfo)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return false
+	}
+	introduced, hasIntroduced := versionedPtr.(introducedInterface)
+	removed, hasRemoved := versionedPtr.(removedInterface)
+	if !hasIntroduced || !hasRemoved {
+		return false
+	}
+	majorIntroduced, minorIntroduced := introduced.APILifecycleIntroduced()
+	majorRemoved, minorRemoved := removed.APILifecycleRemoved()
+	verIntroduced := apimachineryversion.MajorMinor(uint(majorIntroduced), uint(minorIntroduced))
+	verRemoved := apimachineryversion.MajorMinor(uint(majorRemoved), uint(minorRemoved))
+	return verIntroduced.GreaterThan(verRemoved)
+}
+
+// RemovalClass classifies how far in the past a resource was removed relative to the
+// current version, so removal reports can give operators a distinct message after a
+// major upgrade.
+type RemovalClass string
+
+const (
+	// RemovedInCurrentRelease means the removal version equals the current major.minor.
+	RemovedInCurrentRelease RemovalClass = "removed-in-current-release"
+	// RemovedInPriorRelease means the removal version is an earlier minor of the same major.
+	RemovedInPriorRelease RemovalClass = "removed-in-prior-release"
+	// RemovedInPriorMajor means the removal version is from an earlier major version: the
+	// cluster went through a major upgrade since the API was removed.
+	RemovedInPriorMajor RemovalClass = "removed-in-prior-major"
+)
+
+// RemovedAPIClassifications returns, for each resource in the storage map whose declared
+// removal version is at or before the current version, whether it was removed in the
+// current release, a prior minor of the same major, or a prior major version. Resources
+// without a removal version or not yet removed are absent from the result.
+func (e *resourceExpirationEvaluator) RemovedAPIClassifications(groupName string, versioner runtime.ObjectVersioner, versionedResourcesStorageMap map[string]map[string]rest.Storage) map[schema.GroupVersionResource]RemovalClass {
+	classifications := map[schema.GroupVersionResource]RemovalClass{}
+	for apiVersion, versionToResource := range versionedResourcesStorageMap {
+		for resourceName, resourceServingInfo := range versionToResource {
+			gv := schema.GroupVersion{Group: groupName, Version: apiVersion}
+			versionedPtr, err := versionedExampleFor(gv, versioner, resourceServingInfo)
+			if err != nil {
+				utilruntime.HandleError(err)
+				continue
+			}
+
+			removed, ok := versionedPtr.(removedInterface)
+			if !ok {
+				continue
+			}
+			majorRemoved, minorRemoved := removed.APILifecycleRemoved()
+			verRemoved := apimachineryversion.MajorMinor(uint(majorRemoved), uint(minorRemoved))
+			if verRemoved.GreaterThan(e.currentVersion) {
+				continue
+			}
+			switch {
+			case verRemoved.Major() < e.currentVersion.Major():
+				classifications[gv.WithResource(resourceName)] = RemovedInPriorMajor
+			case verRemoved.EqualTo(e.currentVersion):
+				classifications[gv.WithResource(resourceName)] = RemovedInCurrentRelease
+			default:
+				classifications[gv.WithResource(resourceName)] = RemovedInPriorRelease
+			}
+		}
+	}
+	return classifications
+}
+
+// APIsIntroducedInCurrentRelease returns the resources in the storage map whose introduced
+// version equals the current major.minor, for release tooling that reports newly-added APIs.
+// It is a pure query and does not modify the storage map.
+func (e *resourceExpirationEvaluator) APIsIntroducedInCurrentRelease(groupName string, versioner runtime.ObjectVersioner, versionedResourcesStorageMap map[string]map[string]rest.Storage) []schema.GroupVersionResource {
+	introducedNow := []schema.GroupVersionResource{}
+	for apiVersion, versionToResource := range versionedResourcesStorageMap {
+		for resourceName, resourceServingInfo := range versionToResource {
+			gv := schema.GroupVersion{Group: groupName, Version: apiVersion}
+			versionedPtr, err := versionedExampleFor(gv, versioner, resourceServingInfo)
+			if err != nil {
+				utilruntime.HandleError(err)
+				continue
+			}
+
+			introduced, ok := versionedPtr.(introducedInterface)
+			if !ok {
+				continue
+			}
+			majorIntroduced, minorIntroduced := introduced.APILifecycleIntroduced()
+			verIntroduced := apimachineryversion.MajorMinor(uint(majorIntroduced), uint(minorIntroduced))
+			if verIntroduced.EqualTo(e.currentVersion) {
+				introducedNow = append(introducedNow, gv.WithResource(resourceName))
+			}
+		}
+	}
+	sort.Slice(introducedNow, func(i, j int) bool {
+		return introducedNow[i].String() < introducedNow[j].String()
+	})
+	return introducedNow
+}
+
+// ResourceLifecycles returns the declared lifecycle versions for every resource in the storage
+// map that would be served, reusing the same lifecycle probing as shouldServe.
+func (e *resourceExpirationEvaluator) ResourceLifecycles(groupName string, versioner runtime.ObjectVersioner, versionedResourcesStorageMap map[string]map[string]rest.Storage) map[schema.GroupVersionResource]APILifecycle {
+	lifecycles := map[schema.GroupVersionResource]APILifecycle{}
+	for apiVersion, versionToResource := range versionedResourcesStorageMap {
+		for resourceName, resourceServingInfo := range versionToResource {
+			gv := schema.GroupVersion{Group: groupName, Version: apiVersion}
+			// pass the resource name through so a per-resource removal grace callback sees
+			// the same gvr as FilterDeletedKinds and the two stay in agreement.
+			if serve, _ := e.shouldServeWithReadOnly(gv.WithResource(resourceName), versioner, resourceServingInfo); !serve {
+				continue
+			}
+
+			versionedPtr, err := versionedExampleFor(gv, versioner, resourceServingInfo)
+			if err != nil {
+				utilruntime.HandleError(err)
+				continue
+			}
+
+			lifecycle := APILifecycle{}
+			if introduced, ok := versionedPtr.(introducedInterface); ok {
+				majorIntroduced, minorIntroduced := introduced.APILifecycleIntroduced()
+				lifecycle.Introduced = apimachineryversion.MajorMinor(uint(majorIntroduced), uint(minorIntroduced))
+			}
+			if removed, ok := versionedPtr.(removedInterface); ok {
+				majorRemoved, minorRemoved := removed.APILifecycleRemoved()
+				lifecycle.Removed = apimachineryversion.MajorMinor(uint(majorRemoved), uint(minorRemoved))
+			}
+			lifecycles[gv.WithResource(resourceName)] = lifecycle
+		}
+	}
+	return lifecycles
+}
+
+func (e *resourceExpirationEvaluator) InvalidLifecycleResources() []schema.GroupVersionResource {
+	// a resource seen by several FilterDeletedKinds calls is recorded once per call,
+	// e.g. by a preview followed by RemoveDeletedKinds, so dedupe before reporting.
+	invalid := sets.New(e.invalidLifecycleResources...).UnsortedList()
+	sort.Slice(invalid, func(i, j int) bool {
+		return invalid[i].String() < invalid[j].String()
+	})
+	return invalid
+}
+
+func (e *resourceExpirationEvaluator) MissingLifecycleResources() []schema.GroupVersionResource {
+	// a resource seen by several FilterDeletedKinds calls is recorded once per call,
+	// e.g. by a preview followed by RemoveDeletedKinds, so dedupe before reporting.
+	missing := sets.New(e.missingLifecycleResources...).UnsortedList()
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i].String() < missing[j].String()
+	})
+	return missing
+}
+
+func (e *resourceExpirationEvaluator) SetLogVerbosity(level klog.Level) {
+	e.klogVerbosity = level
+}
+
+// shouldServeForVersionWithResource is like ShouldServeForVersion, but applies the
+// per-resource removal grace callback when one is configured.
+func (e *resourceExpirationEvaluator) shouldServeForVersionWithResource(gvr schema.GroupVersionResource, majorRemoved, minorRemoved int) bool {
+	if e.removalGraceReleases != nil {
+		if grace := e.removalGraceReleases(gvr); grace > 0 {
+			removedVer := apimachineryversion.MajorMinor(uint(majorRemoved), uint(minorRemoved))
+			return removedVer.AddMinor(uint(grace)).GreaterThan(e.currentVersion)
+		}
+	}
 	return e.ShouldServeForVersion(majorRemoved, minorRemoved)
 }
 
@@ -148,40 +495,140 @@ type introducedInterface interface {
 	APILifecycleIntroduced() (major, minor int)
 }
 
-// removeDeletedKinds inspects the storage map and modifies it in place by removing storage for kinds that have been deleted.
+// FilterDeletedKinds inspects the storage map and returns a new pruned map without storage for kinds that have been deleted.
+// The input map is not mutated and no storage is destroyed. As a side effect, resources with a
+// missing or invalid lifecycle are recorded on the evaluator for MissingLifecycleResources and
+// InvalidLifecycleResources; repeated calls over the same storage map record them again, and the
+// accessors dedupe.
 // versionedResourcesStorageMap mirrors the field on APIGroupInfo, it's a map from version to resource to the storage.
-func (e *resourceExpirationEvaluator) RemoveDeletedKinds(groupName string, versioner runtime.ObjectVersioner, versionedResourcesStorageMap map[string]map[string]rest.Storage) {
-	versionsToRemove := sets.NewString()
-	for apiVersion := range sets.StringKeySet(versionedResourcesStorageMap) {
+func (e *resourceExpirationEvaluator) FilterDeletedKinds(groupName string, versioner runtime.ObjectVersioner, versionedResourcesStorageMap map[string]map[string]rest.Storage) map[string]map[string]rest.Storage {
+	filteredMap := map[string]map[string]rest.Storage{}
+	// iterate in sorted order so logging and reporting are deterministic across runs.
+	for _, apiVersion := range sets.StringKeySet(versionedResourcesStorageMap).List() {
 		versionToResource := versionedResourcesStorageMap[apiVersion]
 		resourcesToRemove := sets.NewString()
-		for resourceName, resourceServingInfo := range versionToResource {
-			if !e.shouldServe(schema.GroupVersion{Group: groupName, Version: apiVersion}, versioner, resourceServingInfo) {
+		readOnlyResources := sets.NewString()
+		for _, resourceName := range sets.StringKeySet(versionToResource).List() {
+			resourceServingInfo := versionToResource[resourceName]
+			gv := schema.GroupVersion{Group: groupName, Version: apiVersion}
+			if e.hasInvalidLifecycle(gv, versioner, resourceServingInfo) {
+				e.invalidLifecycleResources = append(e.invalidLifecycleResources, gv.WithResource(resourceName))
+			}
+			serve, readOnly := e.shouldServeWithReadOnly(gv.WithResource(resourceName), versioner, resourceServingInfo)
+			if !serve {
 				resourcesToRemove.Insert(resourceName)
+			} else if readOnly {
+				readOnlyResources.Insert(resourceName)
+			} else if e.requireIntroducedVersion && !e.hasIntroducedVersion(gv, versioner, resourceServingInfo) {
+				e.missingLifecycleResources = append(e.missingLifecycleResources, gv.WithResource(resourceName))
 			}
 		}
 
-		for resourceName := range versionedResourcesStorageMap[apiVersion] {
-			if !shouldRemoveResourceAndSubresources(resourcesToRemove, resourceName) {
+		filteredVersion := map[string]rest.Storage{}
+		for _, resourceName := range sets.StringKeySet(versionToResource).List() {
+			if readOnlyResources.Has(resourceName) {
+				klog.V(e.klogVerbosity).Infof("Serving removed resource %v.%v.%v read-only down to the min compatibility version per APILifecycle.", resourceName, apiVersion, groupName)
+				groupResource := schema.GroupResource{Group: groupName, Resource: resourceName}
+				filteredVersion[resourceName] = newRemovedAPIRestrictedStorage(groupResource, versionToResource[resourceName], sets.NewString("get", "list", "watch"))
+				continue
+			}
+			if shouldRemoveResourceAndSubresources(resourcesToRemove, resourceName) {
+				// a subresource that is not expired itself (it would be in resourcesToRemove
+				// otherwise) can outlive its parent and be served standalone.
+				if e.retainUnexpiredSubresources && !resourcesToRemove.Has(resourceName) {
+					klog.V(e.klogVerbosity).Infof("Keeping subresource %v.%v.%v past the removal of its parent because it is not yet time to stop serving it per APILifecycle.", resourceName, apiVersion, groupName)
+					filteredVersion[resourceName] = versionToResource[resourceName]
+					continue
+				}
+				// a removed resource can be retained with a restricted verb set, e.g. to let
+				// controllers clean up existing objects while blocking new writes.
+				if e.removedAPIAllowedVerbs.Len() > 0 {
+					klog.V(e.klogVerbosity).Infof("Serving removed resource %v.%v.%v with only verbs %v per APILifecycle.", resourceName, apiVersion, groupName, e.removedAPIAllowedVerbs.List())
+					groupResource := schema.GroupResource{Group: groupName, Resource: resourceName}
+					filteredVersion[resourceName] = newRemovedAPIRestrictedStorage(groupResource, versionToResource[resourceName], e.removedAPIAllowedVerbs)
+					continue
+				}
+				klog.V(e.klogVerbosity).Infof("Removing resource %v.%v.%v because it is time to stop serving it per APILifecycle.", resourceName, apiVersion, groupName)
 				continue
 			}
+			filteredVersion[resourceName] = versionToResource[resourceName]
+		}
 
-			klog.V(1).Infof("Removing resource %v.%v.%v because it is time to stop serving it per APILifecycle.", resourceName, apiVersion, groupName)
-			storage := versionToResource[resourceName]
-			storage.Destroy()
-			delete(versionToResource, resourceName)
+		if len(filteredVersion) == 0 {
+			klog.V(e.klogVerbosity).Infof("Removing version %v.%v because it is time to stop serving it because it has no resources per APILifecycle.", apiVersion, groupName)
+			continue
 		}
-		versionedResourcesStorageMap[apiVersion] = versionToResource
+		filteredMap[apiVersion] = filteredVersion
+	}
+	return filteredMap
+}
 
-		if len(versionedResourcesStorageMap[apiVersion]) == 0 {
-			versionsToRemove.Insert(apiVersion)
+// removeDeletedKinds inspects the storage map and modifies it in place by removing storage for kinds that have been deleted.
+// versionedResourcesStorageMap mirrors the field on APIGroupInfo, it's a map from version to resource to the storage.
+func (e *resourceExpirationEvaluator) RemoveDeletedKinds(groupName string, versioner runtime.ObjectVersioner, versionedResourcesStorageMap map[string]map[string]rest.Storage) {
+	e.removeDeletedKindsForGroup(groupName, versioner, versionedResourcesStorageMap)
+}
+
+// RemoveDeletedKindsForGroups removes deleted kinds for every group in the map, keyed by group name,
+// and returns the group/version/resources removed across all groups, sorted.
+func (e *resourceExpirationEvaluator) RemoveDeletedKindsForGroups(versioner runtime.ObjectVersioner, versionedResourcesStorageMapByGroup map[string]map[string]map[string]rest.Storage) []schema.GroupVersionResource {
+	removed := []schema.GroupVersionResource{}
+	for groupName, versionedResourcesStorageMap := range versionedResourcesStorageMapByGroup {
+		removed = append(removed, e.removeDeletedKindsForGroup(groupName, versioner, versionedResourcesStorageMap)...)
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].String() < removed[j].String()
+	})
+	return removed
+}
+
+func (e *resourceExpirationEvaluator) removeDeletedKindsForGroup(groupName string, versioner runtime.ObjectVersioner, versionedResourcesStorageMap map[string]map[string]rest.Storage) []schema.GroupVersionResource {
+	removed := []schema.GroupVersionResource{}
+	filteredMap := e.FilterDeletedKinds(groupName, versioner, versionedResourcesStorageMap)
+	// iterate in sorted order so the removal report is ordered by version, then resource.
+	for _, apiVersion := range sets.StringKeySet(versionedResourcesStorageMap).List() {
+		versionToResource := versionedResourcesStorageMap[apiVersion]
+		filteredVersion := filteredMap[apiVersion]
+		for _, resourceName := range sets.StringKeySet(versionToResource).List() {
+			if filteredStorage, ok := filteredVersion[resourceName]; ok {
+				// the filtered entry may be a wrapper around the original storage, e.g. when a
+				// removed resource is retained with a restricted verb set.
+				versionToResource[resourceName] = filteredStorage
+				continue
+			}
+			versionToResource[resourceName].Destroy()
+			delete(versionToResource, resourceName)
+			removed = append(removed, schema.GroupVersionResource{Group: groupName, Version: apiVersion, Resource: resourceName})
+		}
+		if len(versionToResource) == 0 {
+			delete(versionedResourcesStorageMap, apiVersion)
 		}
 	}
+	return removed
+}
+
+// LogEmulationSummary logs a one-time startup summary of the behavior affected by the
+// effective version: the emulation version, how far it is behind the binary version,
+// whether the min compatibility version widens storage decoding, and how many APIs the
+// evaluator pruned because of emulation.
+func LogEmulationSummary(effective utilversion.EffectiveVersion, removed []schema.GroupVersionResource) {
+	klog.Info(emulationSummary(effective, removed))
+}
 
-	for _, apiVersion := range versionsToRemove.List() {
-		klog.V(1).Infof("Removing version %v.%v because it is time to stop serving it because it has no resources per APILifecycle.", apiVersion, groupName)
-		delete(versionedResourcesStorageMap, apiVersion)
+func emulationSummary(effective utilversion.EffectiveVersion, removed []schema.GroupVersionResource) string {
+	binaryVersion := effective.BinaryMajorMinor()
+	emulationVersion := effective.EmulationVersion()
+	minCompatibilityVersion := effective.MinCompatibilityVersion()
+	minorsBehind := 0
+	if binaryVersion.Major() == emulationVersion.Major() && binaryVersion.Minor() >= emulationVersion.Minor() {
+		minorsBehind = int(binaryVersion.Minor() - emulationVersion.Minor())
+	}
+	storageDecoding := "default storage decoding"
+	if minCompatibilityVersion.LessThan(utilversion.SubtractMinorClamped(binaryVersion, 1)) {
+		storageDecoding = "widened storage decoding"
 	}
+	return fmt.Sprintf("Emulation version %s is %d minor(s) behind binary version %s, min compatibility version %s (%s), %d APIs pruned by emulation.",
+		emulationVersion.String(), minorsBehind, binaryVersion.String(), minCompatibilityVersion.String(), storageDecoding, len(removed))
 }
 
 func shouldRemoveResourceAndSubresources(resourcesToRemove sets.String, resourceName string) bool {
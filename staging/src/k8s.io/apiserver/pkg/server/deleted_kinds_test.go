@@ -17,16 +17,28 @@ limitations under the License.
 package server
 
 import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/util/dump"
 	"k8s.io/apimachinery/pkg/util/sets"
 	apimachineryversion "k8s.io/apimachinery/pkg/util/version"
+	openapinamer "k8s.io/apiserver/pkg/endpoints/openapi"
 	"k8s.io/apiserver/pkg/registry/rest"
+	utilversion "k8s.io/component-base/version"
+	"k8s.io/klog/v2"
+	kubeopenapi "k8s.io/kube-openapi/pkg/common"
 )
 
 func Test_newResourceExpirationEvaluator(t *testing.T) {
@@ -39,22 +51,22 @@ func Test_newResourceExpirationEvaluator(t *testing.T) {
 		{
 			name:           "beta",
 			currentVersion: "v1.20.0-beta.0.62+a5d22854a2ac21",
-			expected:       resourceExpirationEvaluator{currentVersion: apimachineryversion.MajorMinor(1, 20)},
+			expected:       resourceExpirationEvaluator{currentVersion: apimachineryversion.MajorMinor(1, 20), klogVerbosity: 1},
 		},
 		{
 			name:           "alpha",
 			currentVersion: "v1.20.0-alpha.0.62+a5d22854a2ac21",
-			expected:       resourceExpirationEvaluator{currentVersion: apimachineryversion.MajorMinor(1, 20), isAlpha: true},
+			expected:       resourceExpirationEvaluator{currentVersion: apimachineryversion.MajorMinor(1, 20), isAlpha: true, klogVerbosity: 1},
 		},
 		{
 			name:           "maintenance",
 			currentVersion: "v1.20.1",
-			expected:       resourceExpirationEvaluator{currentVersion: apimachineryversion.MajorMinor(1, 20)},
+			expected:       resourceExpirationEvaluator{currentVersion: apimachineryversion.MajorMinor(1, 20), klogVerbosity: 1},
 		},
 		{
 			name:           "no v prefix",
 			currentVersion: "1.20.1",
-			expected:       resourceExpirationEvaluator{currentVersion: apimachineryversion.MajorMinor(1, 20)},
+			expected:       resourceExpirationEvaluator{currentVersion: apimachineryversion.MajorMinor(1, 20), klogVerbosity: 1},
 		},
 	}
 	for _, tt := range tests {
@@ -153,6 +165,40 @@ func (r *IntroducedInObj) APILifecycleIntroduced() (major, minor int) {
 	return r.major, r.minor
 }
 
+func storageWithLifecycle(introducedMajor, introducedMinor, removedMajor, removedMinor int) *lifecycleStorage {
+	return &lifecycleStorage{introducedMajor: introducedMajor, introducedMinor: introducedMinor, removedMajor: removedMajor, removedMinor: removedMinor}
+}
+
+type lifecycleStorage struct {
+	introducedMajor, introducedMinor int
+	removedMajor, removedMinor       int
+}
+
+func (r *lifecycleStorage) New() runtime.Object {
+	return &lifecycleObj{introducedMajor: r.introducedMajor, introducedMinor: r.introducedMinor, removedMajor: r.removedMajor, removedMinor: r.removedMinor}
+}
+
+func (r *lifecycleStorage) Destroy() {
+}
+
+type lifecycleObj struct {
+	introducedMajor, introducedMinor int
+	removedMajor, removedMinor       int
+}
+
+func (r *lifecycleObj) GetObjectKind() schema.ObjectKind {
+	panic("don't do this")
+}
+func (r *lifecycleObj) DeepCopyObject() runtime.Object {
+	panic("don't do this either")
+}
+func (r *lifecycleObj) APILifecycleIntroduced() (major, minor int) {
+	return r.introducedMajor, r.introducedMinor
+}
+func (r *lifecycleObj) APILifecycleRemoved() (major, minor int) {
+	return r.removedMajor, r.removedMinor
+}
+
 func Test_resourceExpirationEvaluator_shouldServe(t *testing.T) {
 	tests := []struct {
 		name                        string
@@ -254,6 +300,35 @@ func Test_resourceExpirationEvaluator_shouldServe(t *testing.T) {
 			restStorage: storageIntroducedIn(1, 21),
 			expected:    false,
 		},
+		{
+			name: "introduced-in-future-forward-compatible",
+			resourceExpirationEvaluator: resourceExpirationEvaluator{
+				currentVersion:             apimachineryversion.MajorMinor(1, 20),
+				emulationForwardCompatible: true,
+			},
+			restStorage: storageIntroducedIn(1, 21),
+			expected:    true,
+		},
+		{
+			name: "introduced-within-forward-compatible-ceiling",
+			resourceExpirationEvaluator: resourceExpirationEvaluator{
+				currentVersion:             apimachineryversion.MajorMinor(1, 20),
+				emulationForwardCompatible: true,
+				forwardCompatibleCeiling:   apimachineryversion.MajorMinor(1, 22),
+			},
+			restStorage: storageIntroducedIn(1, 21),
+			expected:    true,
+		},
+		{
+			name: "introduced-beyond-forward-compatible-ceiling",
+			resourceExpirationEvaluator: resourceExpirationEvaluator{
+				currentVersion:             apimachineryversion.MajorMinor(1, 20),
+				emulationForwardCompatible: true,
+				forwardCompatibleCeiling:   apimachineryversion.MajorMinor(1, 22),
+			},
+			restStorage: storageIntroducedIn(1, 23),
+			expected:    false,
+		},
 		{
 			name: "missing-introduced",
 			resourceExpirationEvaluator: resourceExpirationEvaluator{
@@ -341,6 +416,38 @@ func Test_removeDeletedKinds(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "retain-nested-not-expired",
+			resourceExpirationEvaluator: resourceExpirationEvaluator{
+				currentVersion:              apimachineryversion.MajorMinor(1, 20),
+				retainUnexpiredSubresources: true,
+			},
+			versionedResourcesStorageMap: map[string]map[string]rest.Storage{
+				"v1": {
+					"foo":       storageRemovedIn(1, 20),
+					"foo/scale": storageRemovedIn(1, 21),
+				},
+			},
+			expectedStorage: map[string]map[string]rest.Storage{
+				"v1": {
+					"foo/scale": storageRemovedIn(1, 21),
+				},
+			},
+		},
+		{
+			name: "retain-nested-does-not-keep-expired",
+			resourceExpirationEvaluator: resourceExpirationEvaluator{
+				currentVersion:              apimachineryversion.MajorMinor(1, 20),
+				retainUnexpiredSubresources: true,
+			},
+			versionedResourcesStorageMap: map[string]map[string]rest.Storage{
+				"v1": {
+					"foo":       storageRemovedIn(1, 20),
+					"foo/scale": storageRemovedIn(1, 20),
+				},
+			},
+			expectedStorage: map[string]map[string]rest.Storage{},
+		},
 		{
 			name: "remove-all-of-version",
 			resourceExpirationEvaluator: resourceExpirationEvaluator{
@@ -373,6 +480,192 @@ func Test_removeDeletedKinds(t *testing.T) {
 	}
 }
 
+func Test_removeDeletedKindsForGroups(t *testing.T) {
+	evaluator := resourceExpirationEvaluator{
+		currentVersion: apimachineryversion.MajorMinor(1, 20),
+	}
+	versionedResourcesStorageMapByGroup := map[string]map[string]map[string]rest.Storage{
+		"group.one": {
+			"v1": {
+				"twenty":    storageRemovedIn(1, 20),
+				"twentyone": storageRemovedIn(1, 21),
+			},
+		},
+		"group.two": {
+			"v1": {
+				"twenty": storageRemovedIn(1, 20),
+			},
+			"v2": {
+				"twentyone": storageRemovedIn(1, 21),
+			},
+		},
+	}
+	expectedStorage := map[string]map[string]map[string]rest.Storage{
+		"group.one": {
+			"v1": {
+				"twentyone": storageRemovedIn(1, 21),
+			},
+		},
+		"group.two": {
+			"v2": {
+				"twentyone": storageRemovedIn(1, 21),
+			},
+		},
+	}
+	expectedRemoved := []schema.GroupVersionResource{
+		{Group: "group.one", Version: "v1", Resource: "twenty"},
+		{Group: "group.two", Version: "v1", Resource: "twenty"},
+	}
+
+	removed := evaluator.RemoveDeletedKindsForGroups(&dummyConvertor{}, versionedResourcesStorageMapByGroup)
+	if !reflect.DeepEqual(expectedRemoved, removed) {
+		t.Errorf("RemoveDeletedKindsForGroups() = %v, want %v", removed, expectedRemoved)
+	}
+	if !reflect.DeepEqual(expectedStorage, versionedResourcesStorageMapByGroup) {
+		t.Fatal(dump.Pretty(versionedResourcesStorageMapByGroup))
+	}
+}
+
+func Test_removeDeletedKindsReportOrdering(t *testing.T) {
+	evaluator := resourceExpirationEvaluator{
+		currentVersion: apimachineryversion.MajorMinor(1, 20),
+	}
+	buildInput := func() map[string]map[string]rest.Storage {
+		return map[string]map[string]rest.Storage{
+			"v2": {
+				"btwenty": storageRemovedIn(1, 20),
+				"atwenty": storageRemovedIn(1, 20),
+			},
+			"v1": {
+				"twenty":    storageRemovedIn(1, 20),
+				"twentyone": storageRemovedIn(1, 21),
+			},
+		}
+	}
+	expectedRemoved := []schema.GroupVersionResource{
+		{Group: "group.name", Version: "v1", Resource: "twenty"},
+		{Group: "group.name", Version: "v2", Resource: "atwenty"},
+		{Group: "group.name", Version: "v2", Resource: "btwenty"},
+	}
+
+	// the report is sorted by version then resource, and stable across repeated runs.
+	for i := 0; i < 5; i++ {
+		removed := evaluator.removeDeletedKindsForGroup("group.name", &dummyConvertor{}, buildInput())
+		if !reflect.DeepEqual(expectedRemoved, removed) {
+			t.Fatalf("run %d: got %v, want %v", i, removed, expectedRemoved)
+		}
+	}
+}
+
+func Test_filterDeletedKinds(t *testing.T) {
+	evaluator := resourceExpirationEvaluator{
+		currentVersion: apimachineryversion.MajorMinor(1, 20),
+	}
+	versionedResourcesStorageMap := map[string]map[string]rest.Storage{
+		"v1": {
+			"twenty": storageRemovedIn(1, 20),
+		},
+		"v2": {
+			"twenty":    storageRemovedIn(1, 20),
+			"twentyone": storageRemovedIn(1, 21),
+		},
+	}
+	inputCopy := map[string]map[string]rest.Storage{
+		"v1": {
+			"twenty": versionedResourcesStorageMap["v1"]["twenty"],
+		},
+		"v2": {
+			"twenty":    versionedResourcesStorageMap["v2"]["twenty"],
+			"twentyone": versionedResourcesStorageMap["v2"]["twentyone"],
+		},
+	}
+	expectedStorage := map[string]map[string]rest.Storage{
+		"v2": {
+			"twentyone": versionedResourcesStorageMap["v2"]["twentyone"],
+		},
+	}
+
+	actual := evaluator.FilterDeletedKinds("group.name", &dummyConvertor{}, versionedResourcesStorageMap)
+	if !reflect.DeepEqual(expectedStorage, actual) {
+		t.Errorf("unexpected filtered storage map: %v", dump.Pretty(actual))
+	}
+	if !reflect.DeepEqual(inputCopy, versionedResourcesStorageMap) {
+		t.Errorf("expected the input storage map to be untouched, got: %v", dump.Pretty(versionedResourcesStorageMap))
+	}
+}
+
+func Test_missingLifecycleResources(t *testing.T) {
+	tests := []struct {
+		name                         string
+		resourceExpirationEvaluator  resourceExpirationEvaluator
+		versionedResourcesStorageMap map[string]map[string]rest.Storage
+		expectedMissing              []schema.GroupVersionResource
+	}{
+		{
+			name: "strict-collects-missing-introduced",
+			resourceExpirationEvaluator: resourceExpirationEvaluator{
+				currentVersion:           apimachineryversion.MajorMinor(1, 20),
+				requireIntroducedVersion: true,
+			},
+			versionedResourcesStorageMap: map[string]map[string]rest.Storage{
+				"v1": {
+					"withlifecycle": storageIntroducedIn(1, 19),
+					"nolifecycle":   storageIntroducedIn(0, 0),
+				},
+			},
+			expectedMissing: []schema.GroupVersionResource{
+				{Group: "group.name", Version: "v1", Resource: "nolifecycle"},
+			},
+		},
+		{
+			name: "lenient-collects-nothing",
+			resourceExpirationEvaluator: resourceExpirationEvaluator{
+				currentVersion: apimachineryversion.MajorMinor(1, 20),
+			},
+			versionedResourcesStorageMap: map[string]map[string]rest.Storage{
+				"v1": {
+					"withlifecycle": storageIntroducedIn(1, 19),
+					"nolifecycle":   storageIntroducedIn(0, 0),
+				},
+			},
+			expectedMissing: []schema.GroupVersionResource{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			convertor := &dummyConvertor{}
+			tt.resourceExpirationEvaluator.RemoveDeletedKinds("group.name", convertor, tt.versionedResourcesStorageMap)
+			if actual := tt.resourceExpirationEvaluator.MissingLifecycleResources(); !reflect.DeepEqual(tt.expectedMissing, actual) {
+				t.Errorf("MissingLifecycleResources() = %v, want %v", actual, tt.expectedMissing)
+			}
+		})
+	}
+}
+
+func Test_invalidLifecycleResources(t *testing.T) {
+	evaluator := resourceExpirationEvaluator{
+		currentVersion: apimachineryversion.MajorMinor(1, 20),
+	}
+	versionedResourcesStorageMap := map[string]map[string]rest.Storage{
+		"v1": {
+			"backwards":  storageWithLifecycle(1, 25, 1, 22),
+			"consistent": storageWithLifecycle(1, 18, 1, 22),
+		},
+	}
+
+	// previewing with FilterDeletedKinds before applying with RemoveDeletedKinds records the
+	// invalid resource twice; the accessor reports it once.
+	evaluator.FilterDeletedKinds("group.name", &dummyConvertor{}, versionedResourcesStorageMap)
+	evaluator.RemoveDeletedKinds("group.name", &dummyConvertor{}, versionedResourcesStorageMap)
+
+	expectedInvalid := []schema.GroupVersionResource{
+		{Group: "group.name", Version: "v1", Resource: "backwards"},
+	}
+	if actual := evaluator.InvalidLifecycleResources(); !reflect.DeepEqual(expectedInvalid, actual) {
+		t.Errorf("InvalidLifecycleResources() = %v, want %v", actual, expectedInvalid)
+	}
+}
+
 func Test_shouldRemoveResource(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -407,3 +700,470 @@ func Test_shouldRemoveResource(t *testing.T) {
 		})
 	}
 }
+
+type verbRecordingStorage struct {
+	*removedInStorage
+	deleteCalled bool
+}
+
+func (s *verbRecordingStorage) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	return obj, nil
+}
+
+func (s *verbRecordingStorage) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	s.deleteCalled = true
+	return nil, true, nil
+}
+
+func Test_removedAPIAllowedVerbs(t *testing.T) {
+	delegate := &verbRecordingStorage{removedInStorage: storageRemovedIn(1, 20)}
+	resourceExpirationEvaluator := resourceExpirationEvaluator{
+		currentVersion:         apimachineryversion.MajorMinor(1, 20),
+		removedAPIAllowedVerbs: sets.NewString("get", "list", "delete"),
+	}
+
+	filteredMap := resourceExpirationEvaluator.FilterDeletedKinds("group.name", &dummyConvertor{}, map[string]map[string]rest.Storage{
+		"v1": {"twenty": delegate},
+	})
+	retained, ok := filteredMap["v1"]["twenty"]
+	if !ok {
+		t.Fatal("expected the removed resource to be retained with restricted verbs")
+	}
+
+	if _, err := retained.(rest.Creater).Create(context.Background(), delegate.New(), nil, nil); !apierrors.IsMethodNotSupported(err) {
+		t.Errorf("expected method not supported creating through the restricted storage, got: %v", err)
+	}
+	if _, _, err := retained.(rest.GracefulDeleter).Delete(context.Background(), "name", nil, nil); err != nil {
+		t.Errorf("unexpected error deleting through the restricted storage: %v", err)
+	}
+	if !delegate.deleteCalled {
+		t.Error("expected delete to be delegated to the underlying storage")
+	}
+}
+
+func Test_emulationSummary(t *testing.T) {
+	effective := utilversion.NewEffectiveVersion("1.31")
+	effective.SetEmulationVersion(apimachineryversion.MajorMinor(1, 29))
+	effective.SetMinCompatibilityVersion(apimachineryversion.MajorMinor(1, 28))
+	removed := []schema.GroupVersionResource{
+		{Group: "group.name", Version: "v1", Resource: "twenty"},
+		{Group: "group.name", Version: "v1", Resource: "twenty/scale"},
+	}
+
+	actual := emulationSummary(effective, removed)
+	expected := "Emulation version 1.29 is 2 minor(s) behind binary version 1.31, min compatibility version 1.28 (widened storage decoding), 2 APIs pruned by emulation."
+	if actual != expected {
+		t.Errorf("expected summary %q, got %q", expected, actual)
+	}
+
+	// no emulation in effect, default min compatibility.
+	actual = emulationSummary(utilversion.NewEffectiveVersion("1.31"), nil)
+	expected = "Emulation version 1.31 is 0 minor(s) behind binary version 1.31, min compatibility version 1.30 (default storage decoding), 0 APIs pruned by emulation."
+	if actual != expected {
+		t.Errorf("expected summary %q, got %q", expected, actual)
+	}
+}
+
+func Test_shouldServeReadOnlyToMinCompatibility(t *testing.T) {
+	evaluator, err := NewResourceExpirationEvaluatorReadOnlyToMinCompatibility(
+		apimachineryversion.MajorMinor(1, 31), apimachineryversion.MajorMinor(1, 29))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := evaluator.(*resourceExpirationEvaluator)
+	gv := schema.GroupVersion{Group: "group.name", Version: "v1"}
+
+	// removed at 1.30 with min-compat 1.29: kept, but read-only.
+	serve, readOnly := e.shouldServeWithReadOnly(gv.WithResource(""), &dummyConvertor{}, storageRemovedIn(1, 30))
+	if !serve || !readOnly {
+		t.Errorf("expected serve=true readOnly=true, got serve=%v readOnly=%v", serve, readOnly)
+	}
+	// removed at or before min-compat: gone entirely.
+	serve, readOnly = e.shouldServeWithReadOnly(gv.WithResource(""), &dummyConvertor{}, storageRemovedIn(1, 28))
+	if serve || readOnly {
+		t.Errorf("expected serve=false readOnly=false, got serve=%v readOnly=%v", serve, readOnly)
+	}
+	// not yet removed: served normally.
+	serve, readOnly = e.shouldServeWithReadOnly(gv.WithResource(""), &dummyConvertor{}, storageRemovedIn(1, 32))
+	if !serve || readOnly {
+		t.Errorf("expected serve=true readOnly=false, got serve=%v readOnly=%v", serve, readOnly)
+	}
+
+	// the retained storage only answers read verbs.
+	filteredMap := e.FilterDeletedKinds("group.name", &dummyConvertor{}, map[string]map[string]rest.Storage{
+		"v1": {"thirty": storageRemovedIn(1, 30)},
+	})
+	retained, ok := filteredMap["v1"]["thirty"]
+	if !ok {
+		t.Fatal("expected the removed resource to be retained read-only")
+	}
+	if _, err := retained.(rest.Creater).Create(context.Background(), retained.New(), nil, nil); !apierrors.IsMethodNotSupported(err) {
+		t.Errorf("expected method not supported creating through the read-only storage, got: %v", err)
+	}
+}
+
+func Test_resourceLifecycles(t *testing.T) {
+	resourceExpirationEvaluator := resourceExpirationEvaluator{
+		currentVersion: apimachineryversion.MajorMinor(1, 31),
+	}
+	versionedResourcesStorageMap := map[string]map[string]rest.Storage{
+		"v1": {
+			"widgets":    storageWithLifecycle(1, 29, 1, 34),
+			"gadgets":    storageRemovedIn(1, 30),
+			"doohickeys": storageNeverRemoved(),
+		},
+	}
+
+	actual := resourceExpirationEvaluator.ResourceLifecycles("group.name", &dummyConvertor{}, versionedResourcesStorageMap)
+	expected := map[schema.GroupVersionResource]APILifecycle{
+		{Group: "group.name", Version: "v1", Resource: "widgets"}: {
+			Introduced: apimachineryversion.MajorMinor(1, 29),
+			Removed:    apimachineryversion.MajorMinor(1, 34),
+		},
+		{Group: "group.name", Version: "v1", Resource: "doohickeys"}: {},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("ResourceLifecycles() = %v, want %v", actual, expected)
+	}
+}
+
+func Test_APIsIntroducedInCurrentRelease(t *testing.T) {
+	resourceExpirationEvaluator := resourceExpirationEvaluator{
+		currentVersion: apimachineryversion.MajorMinor(1, 31),
+	}
+	versionedResourcesStorageMap := map[string]map[string]rest.Storage{
+		"v1": {
+			"previouses": storageIntroducedIn(1, 30),
+			"currents":   storageIntroducedIn(1, 31),
+			"nexts":      storageIntroducedIn(1, 32),
+			"unversions": storageNeverRemoved(),
+		},
+	}
+
+	actual := resourceExpirationEvaluator.APIsIntroducedInCurrentRelease("group.name", &dummyConvertor{}, versionedResourcesStorageMap)
+	expected := []schema.GroupVersionResource{
+		{Group: "group.name", Version: "v1", Resource: "currents"},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("APIsIntroducedInCurrentRelease() = %v, want %v", actual, expected)
+	}
+}
+
+func Test_removalGraceReleases(t *testing.T) {
+	evaluator, err := NewResourceExpirationEvaluatorWithRemovalGrace(
+		apimachineryversion.MajorMinor(1, 31),
+		func(gvr schema.GroupVersionResource) int {
+			if gvr.Resource == "deferreds" {
+				return 2
+			}
+			return 0
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := evaluator.(*resourceExpirationEvaluator)
+
+	// both resources were removed at 1.30; only the one with a grace of two releases survives.
+	filteredMap := e.FilterDeletedKinds("group.name", &dummyConvertor{}, map[string]map[string]rest.Storage{
+		"v1": {
+			"deferreds": storageRemovedIn(1, 30),
+			"prompts":   storageRemovedIn(1, 30),
+		},
+	})
+	if _, ok := filteredMap["v1"]["deferreds"]; !ok {
+		t.Errorf("expected deferreds to still be served with a removal grace of two releases")
+	}
+	if _, ok := filteredMap["v1"]["prompts"]; ok {
+		t.Errorf("expected prompts to be removed with a removal grace of zero releases")
+	}
+
+	// the lifecycle report sees the same resource names as the grace callback, so it agrees
+	// with what FilterDeletedKinds serves.
+	lifecycles := e.ResourceLifecycles("group.name", &dummyConvertor{}, map[string]map[string]rest.Storage{
+		"v1": {
+			"deferreds": storageRemovedIn(1, 30),
+			"prompts":   storageRemovedIn(1, 30),
+		},
+	})
+	if _, ok := lifecycles[schema.GroupVersionResource{Group: "group.name", Version: "v1", Resource: "deferreds"}]; !ok {
+		t.Errorf("expected a lifecycle for deferreds, which is still served, got %v", lifecycles)
+	}
+	if _, ok := lifecycles[schema.GroupVersionResource{Group: "group.name", Version: "v1", Resource: "prompts"}]; ok {
+		t.Errorf("expected no lifecycle for prompts, which is removed, got %v", lifecycles)
+	}
+}
+
+func Test_RemovedAPIClassifications(t *testing.T) {
+	resourceExpirationEvaluator := resourceExpirationEvaluator{
+		currentVersion: apimachineryversion.MajorMinor(2, 20),
+	}
+	versionedResourcesStorageMap := map[string]map[string]rest.Storage{
+		"v1": {
+			"priormajors":   storageRemovedIn(1, 20),
+			"priorreleases": storageRemovedIn(2, 19),
+			"currents":      storageRemovedIn(2, 20),
+			"futures":       storageRemovedIn(2, 21),
+			"nevers":        storageNeverRemoved(),
+		},
+	}
+
+	actual := resourceExpirationEvaluator.RemovedAPIClassifications("group.name", &dummyConvertor{}, versionedResourcesStorageMap)
+	expected := map[schema.GroupVersionResource]RemovalClass{
+		{Group: "group.name", Version: "v1", Resource: "priormajors"}:   RemovedInPriorMajor,
+		{Group: "group.name", Version: "v1", Resource: "priorreleases"}: RemovedInPriorRelease,
+		{Group: "group.name", Version: "v1", Resource: "currents"}:      RemovedInCurrentRelease,
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("RemovedAPIClassifications() = %v, want %v", actual, expected)
+	}
+}
+
+func Test_logVerbosity(t *testing.T) {
+	resourceExpirationEvaluator := resourceExpirationEvaluator{
+		currentVersion: apimachineryversion.MajorMinor(1, 21),
+		klogVerbosity:  1,
+	}
+	storageMap := map[string]map[string]rest.Storage{
+		"v1": {
+			"twenty": storageRemovedIn(1, 20),
+		},
+	}
+
+	captureLogs := func(fn func()) string {
+		var buf bytes.Buffer
+		klog.SetOutput(&buf)
+		klog.LogToStderr(false)
+		defer klog.LogToStderr(true)
+		fn()
+		klog.Flush()
+		klog.SetOutput(&bytes.Buffer{})
+		return buf.String()
+	}
+
+	// at the default verbosity the removal log is above the global threshold of 0
+	output := captureLogs(func() {
+		resourceExpirationEvaluator.FilterDeletedKinds("group.name", &dummyConvertor{}, storageMap)
+	})
+	if strings.Contains(output, "Removing resource twenty.v1.group.name") {
+		t.Errorf("unexpected removal log at default verbosity: %q", output)
+	}
+
+	// lowering the verbosity to 0 makes the same log pass the threshold
+	resourceExpirationEvaluator.SetLogVerbosity(0)
+	output = captureLogs(func() {
+		resourceExpirationEvaluator.FilterDeletedKinds("group.name", &dummyConvertor{}, storageMap)
+	})
+	if !strings.Contains(output, "Removing resource twenty.v1.group.name") {
+		t.Errorf("expected removal log at verbosity 0, got %q", output)
+	}
+}
+
+// retiredObj is removed in 1.30 and, unlike the other fixtures in this file, is a real
+// API object that can be registered in a scheme and served over HTTP.
+type retiredObj struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+}
+
+func (o *retiredObj) DeepCopyObject() runtime.Object {
+	out := new(retiredObj)
+	out.TypeMeta = o.TypeMeta
+	o.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return out
+}
+func (o *retiredObj) APILifecycleRemoved() (major, minor int) {
+	return 1, 30
+}
+
+type retiredObjList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []retiredObj `json:"items"`
+}
+
+func (o *retiredObjList) DeepCopyObject() runtime.Object {
+	out := new(retiredObjList)
+	out.TypeMeta = o.TypeMeta
+	o.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = append([]retiredObj(nil), o.Items...)
+	return out
+}
+
+// retiredStorage satisfies everything the endpoint installer requires of a storage,
+// so a wrapped version of it must install through an APIGroupInfo as well.
+type retiredStorage struct {
+	gv           schema.GroupVersion
+	deleteCalled bool
+}
+
+func (s *retiredStorage) New() runtime.Object {
+	return &retiredObj{TypeMeta: metav1.TypeMeta{Kind: "Retired", APIVersion: s.gv.String()}}
+}
+
+func (s *retiredStorage) Destroy() {}
+
+func (s *retiredStorage) NamespaceScoped() bool {
+	return false
+}
+
+func (s *retiredStorage) GetSingularName() string {
+	return "retired"
+}
+
+func (s *retiredStorage) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	obj := s.New().(*retiredObj)
+	obj.Name = name
+	return obj, nil
+}
+
+func (s *retiredStorage) NewList() runtime.Object {
+	return &retiredObjList{TypeMeta: metav1.TypeMeta{Kind: "RetiredList", APIVersion: s.gv.String()}}
+}
+
+func (s *retiredStorage) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	return s.NewList(), nil
+}
+
+func (s *retiredStorage) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	return &metav1.Table{}, nil
+}
+
+func (s *retiredStorage) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	s.deleteCalled = true
+	return nil, true, nil
+}
+
+// retiredAPIGroupInfo builds the APIGroupInfo serving the retained storage map, in the
+// same shape TestInstallAPIGroups uses.
+func retiredAPIGroupInfo(gv schema.GroupVersion, storageMap map[string]map[string]rest.Storage) APIGroupInfo {
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gv.WithKind("Retired"), &retiredObj{})
+	scheme.AddKnownTypeWithName(gv.WithKind("RetiredList"), &retiredObjList{})
+	metav1.AddToGroupVersion(scheme, gv)
+	scheme.AddKnownTypes(v1GroupVersion, &metav1.Status{})
+	metav1.AddToGroupVersion(scheme, v1GroupVersion)
+
+	return APIGroupInfo{
+		PrioritizedVersions:          []schema.GroupVersion{gv},
+		VersionedResourcesStorageMap: storageMap,
+		OptionsExternalVersion:       &schema.GroupVersion{Version: "v1"},
+		ParameterCodec:               runtime.NewParameterCodec(scheme),
+		NegotiatedSerializer:         serializer.NewCodecFactory(scheme),
+		Scheme:                       scheme,
+	}
+}
+
+func retiredGetOpenAPIDefinitions(ref kubeopenapi.ReferenceCallback) map[string]kubeopenapi.OpenAPIDefinition {
+	definitions := testGetOpenAPIDefinitions(ref)
+	definitions["k8s.io/apiserver/pkg/server.retiredObj"] = kubeopenapi.OpenAPIDefinition{}
+	definitions["k8s.io/apiserver/pkg/server.retiredObjList"] = kubeopenapi.OpenAPIDefinition{}
+	return definitions
+}
+
+// installRetained installs the filtered storage map and returns a test server for it.
+func installRetained(t *testing.T, gv schema.GroupVersion, storageMap map[string]map[string]rest.Storage) *httptest.Server {
+	t.Helper()
+	config, _ := setUp(t)
+	config.OpenAPIConfig = DefaultOpenAPIConfig(retiredGetOpenAPIDefinitions, openapinamer.NewDefinitionNamer(runtime.NewScheme()))
+	config.OpenAPIConfig.Info.Version = "unversioned"
+	config.OpenAPIV3Config = DefaultOpenAPIV3Config(retiredGetOpenAPIDefinitions, openapinamer.NewDefinitionNamer(runtime.NewScheme()))
+	config.OpenAPIV3Config.Info.Version = "unversioned"
+	s, err := config.Complete(nil).New("test", NewEmptyDelegate())
+	if err != nil {
+		t.Fatalf("error in bringing up the server: %v", err)
+	}
+	api := retiredAPIGroupInfo(gv, storageMap)
+	if err := s.InstallAPIGroup(&api); err != nil {
+		t.Fatalf("error installing the group with a retained removed resource: %v", err)
+	}
+	server := httptest.NewServer(s.Handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func doRequest(t *testing.T, method, url string) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+func Test_removedAPIAllowedVerbsInstall(t *testing.T) {
+	gv := schema.GroupVersion{Group: "retired.example.com", Version: "v1"}
+	delegate := &retiredStorage{gv: gv}
+	resourceExpirationEvaluator := resourceExpirationEvaluator{
+		currentVersion:         apimachineryversion.MajorMinor(1, 30),
+		removedAPIAllowedVerbs: sets.NewString("get", "list"),
+	}
+
+	filteredMap := resourceExpirationEvaluator.FilterDeletedKinds(gv.Group, &dummyConvertor{}, map[string]map[string]rest.Storage{
+		"v1": {"retireds": delegate},
+	})
+	retained, ok := filteredMap["v1"]["retireds"]
+	if !ok {
+		t.Fatal("expected the removed resource to be retained with restricted verbs")
+	}
+	if retained == rest.Storage(delegate) {
+		t.Fatal("expected the retained storage to be wrapped")
+	}
+
+	server := installRetained(t, gv, filteredMap)
+	resourceURL := server.URL + "/apis/retired.example.com/v1/retireds/foo"
+
+	if got := doRequest(t, http.MethodGet, resourceURL); got != http.StatusOK {
+		t.Errorf("expected %d getting through the restricted storage, got %d", http.StatusOK, got)
+	}
+	if got := doRequest(t, http.MethodDelete, resourceURL); got != http.StatusMethodNotAllowed {
+		t.Errorf("expected %d deleting through the restricted storage, got %d", http.StatusMethodNotAllowed, got)
+	}
+	if delegate.deleteCalled {
+		t.Error("expected the disallowed delete to never reach the underlying storage")
+	}
+}
+
+func Test_readOnlyToMinCompatibilityInstall(t *testing.T) {
+	evaluator, err := NewResourceExpirationEvaluatorReadOnlyToMinCompatibility(
+		apimachineryversion.MajorMinor(1, 31), apimachineryversion.MajorMinor(1, 29))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := evaluator.(*resourceExpirationEvaluator)
+
+	gv := schema.GroupVersion{Group: "retired.example.com", Version: "v1"}
+	delegate := &retiredStorage{gv: gv}
+	// removed at 1.30 with min-compat 1.29: kept, but read-only.
+	filteredMap := e.FilterDeletedKinds(gv.Group, &dummyConvertor{}, map[string]map[string]rest.Storage{
+		"v1": {"retireds": delegate},
+	})
+	retained, ok := filteredMap["v1"]["retireds"]
+	if !ok {
+		t.Fatal("expected the removed resource to be retained read-only")
+	}
+	if retained == rest.Storage(delegate) {
+		t.Fatal("expected the retained storage to be wrapped")
+	}
+
+	server := installRetained(t, gv, filteredMap)
+	collectionURL := server.URL + "/apis/retired.example.com/v1/retireds"
+	resourceURL := collectionURL + "/foo"
+
+	if got := doRequest(t, http.MethodGet, resourceURL); got != http.StatusOK {
+		t.Errorf("expected %d getting through the read-only storage, got %d", http.StatusOK, got)
+	}
+	if got := doRequest(t, http.MethodGet, collectionURL); got != http.StatusOK {
+		t.Errorf("expected %d listing through the read-only storage, got %d", http.StatusOK, got)
+	}
+	if got := doRequest(t, http.MethodDelete, resourceURL); got != http.StatusMethodNotAllowed {
+		t.Errorf("expected %d deleting through the read-only storage, got %d", http.StatusMethodNotAllowed, got)
+	}
+	if delegate.deleteCalled {
+		t.Error("expected the disallowed delete to never reach the underlying storage")
+	}
+}
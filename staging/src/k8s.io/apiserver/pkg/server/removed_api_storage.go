@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// removedAPIRestrictedStorage wraps the storage of a removed resource that is retained
+// with a restricted verb set. Verbs outside the allowed set, and verbs the delegate does
+// not implement, answer with a Method Not Allowed error. Because the wrapper advertises
+// every verb interface, the restriction is enforced per request rather than by the set
+// of interfaces the storage satisfies. The non-verb interfaces the endpoint installer
+// consults — scope, singular name, categories and storage version — are forwarded to
+// the delegate so that a retained resource still installs and appears in discovery.
+type removedAPIRestrictedStorage struct {
+	rest.Storage
+	groupResource schema.GroupResource
+	allowedVerbs  sets.String
+}
+
+// newRemovedAPIRestrictedStorage builds the restricted storage for a retained removed
+// resource. rest.KindProvider and rest.GroupVersionKindProvider are only present on the
+// returned storage when the delegate implements them, because the endpoint installer and
+// versionedExampleFor fall back to scheme-based kind resolution in their absence.
+func newRemovedAPIRestrictedStorage(groupResource schema.GroupResource, delegate rest.Storage, allowedVerbs sets.String) rest.Storage {
+	restricted := &removedAPIRestrictedStorage{
+		Storage:       delegate,
+		groupResource: groupResource,
+		allowedVerbs:  allowedVerbs,
+	}
+	kindProvider, isKindProvider := delegate.(rest.KindProvider)
+	gvkProvider, isGVKProvider := delegate.(rest.GroupVersionKindProvider)
+	switch {
+	case isKindProvider && isGVKProvider:
+		return &struct {
+			*removedAPIRestrictedStorage
+			rest.KindProvider
+			rest.GroupVersionKindProvider
+		}{restricted, kindProvider, gvkProvider}
+	case isKindProvider:
+		return &struct {
+			*removedAPIRestrictedStorage
+			rest.KindProvider
+		}{restricted, kindProvider}
+	case isGVKProvider:
+		return &struct {
+			*removedAPIRestrictedStorage
+			rest.GroupVersionKindProvider
+		}{restricted, gvkProvider}
+	default:
+		return restricted
+	}
+}
+
+func (s *removedAPIRestrictedStorage) NamespaceScoped() bool {
+	if scoper, ok := s.Storage.(rest.Scoper); ok {
+		return scoper.NamespaceScoped()
+	}
+	return false
+}
+
+func (s *removedAPIRestrictedStorage) GetSingularName() string {
+	if provider, ok := s.Storage.(rest.SingularNameProvider); ok {
+		return provider.GetSingularName()
+	}
+	return ""
+}
+
+func (s *removedAPIRestrictedStorage) Categories() []string {
+	if provider, ok := s.Storage.(rest.CategoriesProvider); ok {
+		return provider.Categories()
+	}
+	return nil
+}
+
+func (s *removedAPIRestrictedStorage) StorageVersion() runtime.GroupVersioner {
+	if provider, ok := s.Storage.(rest.StorageVersionProvider); ok {
+		return provider.StorageVersion()
+	}
+	return nil
+}
+
+func (s *removedAPIRestrictedStorage) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	getter, ok := s.Storage.(rest.Getter)
+	if !s.allowedVerbs.Has("get") || !ok {
+		return nil, apierrors.NewMethodNotSupported(s.groupResource, "get")
+	}
+	return getter.Get(ctx, name, options)
+}
+
+func (s *removedAPIRestrictedStorage) NewList() runtime.Object {
+	if lister, ok := s.Storage.(rest.Lister); ok {
+		return lister.NewList()
+	}
+	return s.Storage.New()
+}
+
+func (s *removedAPIRestrictedStorage) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	lister, ok := s.Storage.(rest.Lister)
+	if !s.allowedVerbs.Has("list") || !ok {
+		return nil, apierrors.NewMethodNotSupported(s.groupResource, "list")
+	}
+	return lister.List(ctx, options)
+}
+
+func (s *removedAPIRestrictedStorage) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	convertor, ok := s.Storage.(rest.TableConvertor)
+	if !ok {
+		return nil, apierrors.NewMethodNotSupported(s.groupResource, "convertToTable")
+	}
+	return convertor.ConvertToTable(ctx, object, tableOptions)
+}
+
+func (s *removedAPIRestrictedStorage) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	creater, ok := s.Storage.(rest.Creater)
+	if !s.allowedVerbs.Has("create") || !ok {
+		return nil, apierrors.NewMethodNotSupported(s.groupResource, "create")
+	}
+	return creater.Create(ctx, obj, createValidation, options)
+}
+
+func (s *removedAPIRestrictedStorage) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	updater, ok := s.Storage.(rest.Updater)
+	if !s.allowedVerbs.Has("update") || !ok {
+		return nil, false, apierrors.NewMethodNotSupported(s.groupResource, "update")
+	}
+	return updater.Update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, options)
+}
+
+func (s *removedAPIRestrictedStorage) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	deleter, ok := s.Storage.(rest.GracefulDeleter)
+	if !s.allowedVerbs.Has("delete") || !ok {
+		return nil, false, apierrors.NewMethodNotSupported(s.groupResource, "delete")
+	}
+	return deleter.Delete(ctx, name, deleteValidation, options)
+}
+
+func (s *removedAPIRestrictedStorage) DeleteCollection(ctx context.Context, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions, listOptions *metainternalversion.ListOptions) (runtime.Object, error) {
+	deleter, ok := s.Storage.(rest.CollectionDeleter)
+	if !s.allowedVerbs.Has("deletecollection") || !ok {
+		return nil, apierrors.NewMethodNotSupported(s.groupResource, "deletecollection")
+	}
+	return deleter.DeleteCollection(ctx, deleteValidation, options, listOptions)
+}
+
+func (s *removedAPIRestrictedStorage) Watch(ctx context.Context, options *metainternalversion.ListOptions) (watch.Interface, error) {
+	watcher, ok := s.Storage.(rest.Watcher)
+	if !s.allowedVerbs.Has("watch") || !ok {
+		return nil, apierrors.NewMethodNotSupported(s.groupResource, "watch")
+	}
+	return watcher.Watch(ctx, options)
+}
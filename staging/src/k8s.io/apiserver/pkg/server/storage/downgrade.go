@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	apimachineryversion "k8s.io/apimachinery/pkg/util/version"
+	version "k8s.io/component-base/version"
+)
+
+// StorageVersionChange records the storage version movement needed for a group/kind
+// before downgrading to a target version.
+type StorageVersionChange struct {
+	CurrentStorageVersion schema.GroupVersion
+	TargetStorageVersion  schema.GroupVersion
+}
+
+// DowngradeReport summarizes the impact of downgrading to a target version.
+type DowngradeReport struct {
+	// RemovedAPIs lists the enabled group/version/kinds that would no longer be served
+	// by a binary of the target version because they are introduced after it.
+	RemovedAPIs []schema.GroupVersionKind
+	// StorageMigrations lists the group/kinds whose storage version selected by a binary of
+	// the target version differs from the current storage version, and therefore would need
+	// storage migration before the downgrade.
+	StorageMigrations map[schema.GroupKind]StorageVersionChange
+}
+
+// DowngradePreview reports which enabled APIs would stop being served and which kinds would
+// need storage migration if the cluster were downgraded to the target version.
+// Group/versions disabled in resourceConfig are excluded from the report.
+// Note that per-resource encoding overrides in the encoding config are not consulted,
+// since the scheme has no knowledge of resource names.
+func DowngradePreview(scheme *runtime.Scheme, resourceConfig *ResourceConfig, encoding *DefaultResourceEncodingConfig, target *apimachineryversion.Version) DowngradeReport {
+	report := DowngradeReport{StorageMigrations: map[schema.GroupKind]StorageVersionChange{}}
+	if scheme == nil || target == nil {
+		return report
+	}
+
+	var currentEffectiveVersion version.EffectiveVersion
+	if encoding != nil {
+		currentEffectiveVersion = encoding.effectiveVersion
+	}
+	// a binary of the target version retains compatibility with at most the target version.
+	targetEffectiveVersion := version.NewEffectiveVersion(target.String())
+	targetEffectiveVersion.SetMinCompatibilityVersion(target)
+
+	// examples records one group/version/kind per enabled group/kind to compute storage versions with.
+	examples := map[schema.GroupKind]schema.GroupVersionKind{}
+	for gvk := range scheme.AllKnownTypes() {
+		if gvk.Version == runtime.APIVersionInternal {
+			continue
+		}
+		if resourceConfig != nil && !resourceConfig.versionEnabled(gvk.GroupVersion()) {
+			continue
+		}
+		example, err := scheme.New(gvk)
+		if err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		if introduced, ok := example.(introducedInterface); ok {
+			majorIntroduced, minorIntroduced := introduced.APILifecycleIntroduced()
+			introducedVer := apimachineryversion.MajorMinor(uint(majorIntroduced), uint(minorIntroduced))
+			if introducedVer.GreaterThan(target) {
+				report.RemovedAPIs = append(report.RemovedAPIs, gvk)
+			}
+		}
+		if _, ok := examples[gvk.GroupKind()]; !ok {
+			examples[gvk.GroupKind()] = gvk
+		}
+	}
+	sort.Slice(report.RemovedAPIs, func(i, j int) bool {
+		return report.RemovedAPIs[i].String() < report.RemovedAPIs[j].String()
+	})
+
+	for groupKind, gvk := range examples {
+		example, err := scheme.New(gvk)
+		if err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		prioritizedVersions := scheme.PrioritizedVersionsForGroup(groupKind.Group)
+		if len(prioritizedVersions) == 0 {
+			continue
+		}
+		binaryStorageVersion := prioritizedVersions[0]
+		currentStorageVersion, err := emulatedStorageVersion(binaryStorageVersion, example, currentEffectiveVersion, scheme)
+		if err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		targetStorageVersion, err := emulatedStorageVersion(binaryStorageVersion, example, targetEffectiveVersion, scheme)
+		if err != nil {
+			utilruntime.HandleError(err)
+			continue
+		}
+		if currentStorageVersion != targetStorageVersion {
+			report.StorageMigrations[groupKind] = StorageVersionChange{
+				CurrentStorageVersion: currentStorageVersion,
+				TargetStorageVersion:  targetStorageVersion,
+			}
+		}
+	}
+	return report
+}
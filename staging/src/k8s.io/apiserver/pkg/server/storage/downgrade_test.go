@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/component-base/version"
+)
+
+// JobV2 is introduced in 1.33 and is the preferred storage version.
+type JobV2 struct {
+	A string `json:"A,omitempty"`
+}
+
+func (*JobV2) GetObjectKind() schema.ObjectKind { panic("not implemented") }
+func (*JobV2) DeepCopyObject() runtime.Object   { panic("not implemented") }
+func (*JobV2) APILifecycleIntroduced() (major, minor int) {
+	return 1, 33
+}
+
+// JobV1 is introduced in 1.28.
+type JobV1 struct {
+	A string `json:"A,omitempty"`
+}
+
+func (*JobV1) GetObjectKind() schema.ObjectKind { panic("not implemented") }
+func (*JobV1) DeepCopyObject() runtime.Object   { panic("not implemented") }
+func (*JobV1) APILifecycleIntroduced() (major, minor int) {
+	return 1, 28
+}
+
+var jobV2 = schema.GroupVersion{Group: "job.example.com", Version: "v2"}
+var jobV1 = schema.GroupVersion{Group: "job.example.com", Version: "v1"}
+
+func jobScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	// v2 is registered first, so it is the preferred version of the group.
+	s.AddKnownTypeWithName(jobV2.WithKind("Job"), &JobV2{})
+	s.AddKnownTypeWithName(jobV1.WithKind("Job"), &JobV1{})
+	return s
+}
+
+func TestDowngradePreview(t *testing.T) {
+	scheme := jobScheme()
+	encoding := NewDefaultResourceEncodingConfig(scheme)
+	encoding.SetEffectiveVersion(version.NewEffectiveVersion("1.34"))
+
+	resourceConfig := NewResourceConfig()
+	resourceConfig.EnableVersions(jobV1, jobV2)
+
+	report := DowngradePreview(scheme, resourceConfig, encoding, utilversion.MustParse("1.30"))
+
+	// jobV2 is introduced in 1.33, so it stops being served at 1.30.
+	expectedRemoved := []schema.GroupVersionKind{jobV2.WithKind("Job")}
+	if !reflect.DeepEqual(expectedRemoved, report.RemovedAPIs) {
+		t.Errorf("expected removed APIs %v, got %v", expectedRemoved, report.RemovedAPIs)
+	}
+
+	// the current binary stores Jobs in v2, but a 1.30 binary only understands v1.
+	expectedMigrations := map[schema.GroupKind]StorageVersionChange{
+		{Group: "job.example.com", Kind: "Job"}: {
+			CurrentStorageVersion: jobV2,
+			TargetStorageVersion:  jobV1,
+		},
+	}
+	if !reflect.DeepEqual(expectedMigrations, report.StorageMigrations) {
+		t.Errorf("expected storage migrations %v, got %v", expectedMigrations, report.StorageMigrations)
+	}
+
+	// nothing is reported for disabled group/versions.
+	resourceConfig.DisableVersions(jobV1, jobV2)
+	report = DowngradePreview(scheme, resourceConfig, encoding, utilversion.MustParse("1.30"))
+	if len(report.RemovedAPIs) != 0 || len(report.StorageMigrations) != 0 {
+		t.Errorf("expected empty report for disabled group/versions, got %+v", report)
+	}
+}
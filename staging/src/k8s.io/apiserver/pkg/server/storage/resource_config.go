@@ -17,7 +17,17 @@ limitations under the License.
 package storage
 
 import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/klog/v2"
 )
 
 // APIResourceConfigSource is the interface to determine which groups and versions are enabled
@@ -28,13 +38,82 @@ type APIResourceConfigSource interface {
 
 var _ APIResourceConfigSource = &ResourceConfig{}
 
+// ResourceLifecycle records the version window in which a resource is available.
+type ResourceLifecycle struct {
+	// IntroducedVersion is the first version the resource is available in.
+	// If nil, the resource is considered to have always been available.
+	IntroducedVersion *version.Version
+	// RemovedVersion is the first version the resource is no longer available in.
+	// If nil, the resource has not been removed.
+	RemovedVersion *version.Version
+}
+
 type ResourceConfig struct {
 	GroupVersionConfigs map[schema.GroupVersion]bool
 	ResourceConfigs     map[schema.GroupVersionResource]bool
+	// emulationVersion, if set, additionally gates the default enablement of a resource on its lifecycle window.
+	// Explicit preferences on individual resources take priority over the lifecycle.
+	emulationVersion *version.Version
+	// resourceLifecycles records the lifecycle windows evaluated against emulationVersion.
+	// Resources without a recorded lifecycle are considered always available.
+	resourceLifecycles map[schema.GroupVersionResource]ResourceLifecycle
+	// versionSources records which source last enabled or disabled each group/version,
+	// for debugging how the config was assembled. Entries set without a source are absent.
+	versionSources map[schema.GroupVersion]string
+	// versionDefaultDeny marks enabled group/versions whose unlisted resources default to
+	// disabled instead of enabled, for allow-list style version enablement.
+	versionDefaultDeny map[schema.GroupVersion]bool
+	// resourceEmulationOverrides pins the emulation version individual resources are
+	// evaluated against, instead of the emulation version of the config.
+	resourceEmulationOverrides map[schema.GroupVersionResource]*version.Version
 }
 
 func NewResourceConfig() *ResourceConfig {
-	return &ResourceConfig{GroupVersionConfigs: map[schema.GroupVersion]bool{}, ResourceConfigs: map[schema.GroupVersionResource]bool{}}
+	return &ResourceConfig{
+		GroupVersionConfigs: map[schema.GroupVersion]bool{},
+		ResourceConfigs:     map[schema.GroupVersionResource]bool{},
+		resourceLifecycles:  map[schema.GroupVersionResource]ResourceLifecycle{},
+		versionSources:      map[schema.GroupVersion]string{},
+		versionDefaultDeny:  map[schema.GroupVersion]bool{},
+
+		resourceEmulationOverrides: map[schema.GroupVersionResource]*version.Version{},
+	}
+}
+
+// SetResourceEmulationOverride pins the emulation version the resource is evaluated
+// against, instead of the emulation version of the config, e.g. to keep one experimental
+// resource served during a phased rollout. A nil version removes the override.
+func (o *ResourceConfig) SetResourceEmulationOverride(gvr schema.GroupVersionResource, v *version.Version) {
+	if v == nil {
+		delete(o.resourceEmulationOverrides, gvr)
+		return
+	}
+	o.resourceEmulationOverrides[gvr] = v
+}
+
+// NewResourceConfigWith returns a ResourceConfig built by running the configure closure against
+// a fresh config, then validating the result once. This gives callers that layer many
+// Enable/Disable calls a single transactional boundary instead of partially-applied state.
+func NewResourceConfigWith(configure func(*ResourceConfig)) (*ResourceConfig, error) {
+	config := NewResourceConfig()
+	configure(config)
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// validate reports contradictions in the assembled config.
+func (o *ResourceConfig) validate() error {
+	errs := []error{}
+	for resource, lifecycle := range o.resourceLifecycles {
+		if lifecycle.IntroducedVersion != nil && lifecycle.RemovedVersion != nil &&
+			lifecycle.IntroducedVersion.GreaterThan(lifecycle.RemovedVersion) {
+			errs = append(errs, fmt.Errorf("resource %v introduced at %s after it is removed at %s",
+				resource, lifecycle.IntroducedVersion.String(), lifecycle.RemovedVersion.String()))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
 }
 
 // DisableMatchingVersions disables all group/versions for which the matcher function returns true.
@@ -80,22 +159,67 @@ func (o *ResourceConfig) removeMatchingResourcePreferences(matcher func(gvr sche
 	}
 }
 
+// logEnablementChange traces an enable/disable call together with its caller at high
+// verbosity, for debugging how a final config was assembled. The caller lookup only
+// runs when the verbosity is enabled, so the trace is free in production.
+func logEnablementChange(op string, affected []string) {
+	if !klog.V(5).Enabled() {
+		return
+	}
+	caller := "unknown"
+	if _, file, line, ok := runtime.Caller(2); ok {
+		caller = fmt.Sprintf("%s:%d", file, line)
+	}
+	klog.V(5).Infof("ResourceConfig.%s(%s) called from %s", op, strings.Join(affected, ","), caller)
+}
+
+func groupVersionStrings(versions []schema.GroupVersion) []string {
+	out := make([]string, 0, len(versions))
+	for _, version := range versions {
+		out = append(out, version.String())
+	}
+	return out
+}
+
+func resourceStrings(resources []schema.GroupVersionResource) []string {
+	out := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		out = append(out, resource.String())
+	}
+	return out
+}
+
 // DisableVersions disables the versions entirely.
 // This will remove any preferences previously set on individual resources.
 func (o *ResourceConfig) DisableVersions(versions ...schema.GroupVersion) {
+	logEnablementChange("DisableVersions", groupVersionStrings(versions))
 	for _, version := range versions {
 		o.GroupVersionConfigs[version] = false
+		delete(o.versionSources, version)
+		delete(o.versionDefaultDeny, version)
 
 		// a preference about a version takes priority over the previously set resources
 		o.removeMatchingResourcePreferences(resourceMatcherForVersion(version))
 	}
 }
 
+// DisableVersionsFromSource disables the versions like DisableVersions, and records the
+// source making the change for later retrieval via SourceFor.
+func (o *ResourceConfig) DisableVersionsFromSource(source string, versions ...schema.GroupVersion) {
+	o.DisableVersions(versions...)
+	for _, version := range versions {
+		o.versionSources[version] = source
+	}
+}
+
 // EnableVersions enables all resources in a given groupVersion.
 // This will remove any preferences previously set on individual resources.
 func (o *ResourceConfig) EnableVersions(versions ...schema.GroupVersion) {
+	logEnablementChange("EnableVersions", groupVersionStrings(versions))
 	for _, version := range versions {
 		o.GroupVersionConfigs[version] = true
+		delete(o.versionSources, version)
+		delete(o.versionDefaultDeny, version)
 
 		// a preference about a version takes priority over the previously set resources
 		o.removeMatchingResourcePreferences(resourceMatcherForVersion(version))
@@ -103,6 +227,21 @@ func (o *ResourceConfig) EnableVersions(versions ...schema.GroupVersion) {
 
 }
 
+// EnableVersionsFromSource enables the versions like EnableVersions, and records the
+// source making the change for later retrieval via SourceFor.
+func (o *ResourceConfig) EnableVersionsFromSource(source string, versions ...schema.GroupVersion) {
+	o.EnableVersions(versions...)
+	for _, version := range versions {
+		o.versionSources[version] = source
+	}
+}
+
+// SourceFor returns the source recorded for the last change to the enablement of the
+// group/version, or the empty string if the last change did not carry a source.
+func (o *ResourceConfig) SourceFor(gv schema.GroupVersion) string {
+	return o.versionSources[gv]
+}
+
 // TODO this must be removed and we enable/disable individual resources.
 func (o *ResourceConfig) versionEnabled(version schema.GroupVersion) bool {
 	enabled, _ := o.GroupVersionConfigs[version]
@@ -110,18 +249,110 @@ func (o *ResourceConfig) versionEnabled(version schema.GroupVersion) bool {
 }
 
 func (o *ResourceConfig) DisableResources(resources ...schema.GroupVersionResource) {
+	logEnablementChange("DisableResources", resourceStrings(resources))
 	for _, resource := range resources {
 		o.ResourceConfigs[resource] = false
 	}
 }
 
 func (o *ResourceConfig) EnableResources(resources ...schema.GroupVersionResource) {
+	logEnablementChange("EnableResources", resourceStrings(resources))
 	for _, resource := range resources {
 		o.ResourceConfigs[resource] = true
 	}
 }
 
+// EnableResourcesChecked enables the resources the given RESTMapper-like lookup function
+// knows a kind for, and returns the subset it does not, unmodified. This surfaces typos in
+// a runtime-config value at parse time instead of silently never serving the resource.
+func (o *ResourceConfig) EnableResourcesChecked(kindsFor func(resource schema.GroupVersionResource) ([]schema.GroupVersionKind, error), resources ...schema.GroupVersionResource) []schema.GroupVersionResource {
+	rejected := []schema.GroupVersionResource{}
+	for _, resource := range resources {
+		if kinds, err := kindsFor(resource); err != nil || len(kinds) == 0 {
+			rejected = append(rejected, resource)
+			continue
+		}
+		o.EnableResources(resource)
+	}
+	return rejected
+}
+
+// DisableResourcesChecked disables the resources the given RESTMapper-like lookup function
+// knows a kind for, and returns the subset it does not, unmodified.
+func (o *ResourceConfig) DisableResourcesChecked(kindsFor func(resource schema.GroupVersionResource) ([]schema.GroupVersionKind, error), resources ...schema.GroupVersionResource) []schema.GroupVersionResource {
+	rejected := []schema.GroupVersionResource{}
+	for _, resource := range resources {
+		if kinds, err := kindsFor(resource); err != nil || len(kinds) == 0 {
+			rejected = append(rejected, resource)
+			continue
+		}
+		o.DisableResources(resource)
+	}
+	return rejected
+}
+
+// ClearResource removes the explicit preference on the resource, if any, so the resource
+// falls back to the enablement of its group/version.
+func (o *ResourceConfig) ClearResource(resource schema.GroupVersionResource) {
+	delete(o.ResourceConfigs, resource)
+}
+
+// ClearVersion removes the explicit decision on the group/version, if any, so it reverts
+// to the default of the config: an unmentioned group/version is disabled. The recorded
+// source of the decision is dropped along with it.
+func (o *ResourceConfig) ClearVersion(gv schema.GroupVersion) {
+	delete(o.GroupVersionConfigs, gv)
+	delete(o.versionSources, gv)
+	delete(o.versionDefaultDeny, gv)
+}
+
+// EnableVersionWithOnlyResources enables the group/version, but with unlisted resources
+// defaulting to disabled instead of enabled: only the listed resources are served. This is
+// the allow-list counterpart of enabling a version and disabling unwanted resources one by
+// one. Preferences previously set on individual resources of the version are removed.
+func (o *ResourceConfig) EnableVersionWithOnlyResources(gv schema.GroupVersion, resources sets.String) {
+	o.EnableVersions(gv)
+	o.versionDefaultDeny[gv] = true
+	for _, resource := range resources.List() {
+		o.EnableResources(gv.WithResource(resource))
+	}
+}
+
+// SetResourceLifecycle records the lifecycle window used for the availability checks of the resource.
+func (o *ResourceConfig) SetResourceLifecycle(resource schema.GroupVersionResource, lifecycle ResourceLifecycle) {
+	o.resourceLifecycles[resource] = lifecycle
+}
+
+// EnableIntroducedUpTo enables each candidate whose introduced version per the recorded
+// lifecycle is at most v, leaving the other candidates untouched. Candidates without a
+// recorded introduced version are considered to have always been available and are enabled.
+func (o *ResourceConfig) EnableIntroducedUpTo(v *version.Version, candidates []schema.GroupVersionResource) {
+	for _, candidate := range candidates {
+		lifecycle, ok := o.resourceLifecycles[candidate]
+		if ok && lifecycle.IntroducedVersion != nil && lifecycle.IntroducedVersion.GreaterThan(v) {
+			continue
+		}
+		o.EnableResources(candidate)
+	}
+}
+
+// IsEmpty returns true when the config has no explicit group/version or resource entries,
+// distinguishing "nothing configured" from "everything explicitly disabled".
+func (o *ResourceConfig) IsEmpty() bool {
+	return len(o.GroupVersionConfigs) == 0 && len(o.ResourceConfigs) == 0
+}
+
+// EmulationVersion returns the emulation version the config evaluates resource availability
+// against, or nil when the config has none and lifecycle windows are ignored.
+func (o *ResourceConfig) EmulationVersion() *version.Version {
+	return o.emulationVersion
+}
+
 func (o *ResourceConfig) ResourceEnabled(resource schema.GroupVersionResource) bool {
+	return o.resourceEnabledAtEmulationVersion(resource, o.emulationVersion)
+}
+
+func (o *ResourceConfig) resourceEnabledAtEmulationVersion(resource schema.GroupVersionResource, v *version.Version) bool {
 	// if a resource is explicitly set, that takes priority over the preference of the version.
 	resourceEnabled, explicitlySet := o.ResourceConfigs[resource]
 	if explicitlySet {
@@ -131,20 +362,548 @@ func (o *ResourceConfig) ResourceEnabled(resource schema.GroupVersionResource) b
 	if !o.versionEnabled(resource.GroupVersion()) {
 		return false
 	}
-	// they are enabled by default.
+	// an allow-list version serves only its explicitly enabled resources.
+	if o.versionDefaultDeny[resource.GroupVersion()] {
+		return false
+	}
+	// they are enabled by default if available at the emulation version.
+	return o.apiAvailable(resource, v)
+}
+
+// apiAvailable returns true if the lifecycle window of the resource includes the given emulation version.
+// A per-resource emulation override takes priority over the given version.
+func (o *ResourceConfig) apiAvailable(resource schema.GroupVersionResource, v *version.Version) bool {
+	if override, ok := o.resourceEmulationOverrides[resource]; ok {
+		v = override
+	}
+	if v == nil {
+		return true
+	}
+	lifecycle, ok := o.resourceLifecycles[resource]
+	if !ok {
+		return true
+	}
+	if lifecycle.IntroducedVersion != nil && v.LessThan(lifecycle.IntroducedVersion) {
+		return false
+	}
+	if lifecycle.RemovedVersion != nil && v.AtLeast(lifecycle.RemovedVersion) {
+		return false
+	}
 	return true
 }
 
+// AtEmulationVersion returns a read-only view of the config evaluated at the given emulation version.
+// The view shares the enable/disable maps of the parent config, so subsequent changes to the parent
+// are visible through it; only the emulation version used for availability differs.
+func (o *ResourceConfig) AtEmulationVersion(v *version.Version) APIResourceConfigSource {
+	return &emulatedResourceConfig{parent: o, emulationVersion: v}
+}
+
+type emulatedResourceConfig struct {
+	parent           *ResourceConfig
+	emulationVersion *version.Version
+}
+
+var _ APIResourceConfigSource = &emulatedResourceConfig{}
+
+func (c *emulatedResourceConfig) ResourceEnabled(resource schema.GroupVersionResource) bool {
+	return c.parent.resourceEnabledAtEmulationVersion(resource, c.emulationVersion)
+}
+
+func (c *emulatedResourceConfig) AnyResourceForGroupEnabled(group string) bool {
+	return c.parent.anyResourceForGroupEnabledAtEmulationVersion(group, c.emulationVersion)
+}
+
+// EnabledResourcesForVersion returns the subset of candidates under the given group/version
+// that are enabled in this config. Candidates outside the group/version are ignored.
+func (o *ResourceConfig) EnabledResourcesForVersion(gv schema.GroupVersion, candidates []schema.GroupVersionResource) []schema.GroupVersionResource {
+	enabled := []schema.GroupVersionResource{}
+	for _, candidate := range candidates {
+		if candidate.GroupVersion() != gv {
+			continue
+		}
+		if o.ResourceEnabled(candidate) {
+			enabled = append(enabled, candidate)
+		}
+	}
+	return enabled
+}
+
+// CountEnabled returns how many of the given group/version and resource candidates are
+// enabled in this config, for feeding an enabled-APIs gauge at startup.
+func (o *ResourceConfig) CountEnabled(knownGVs []schema.GroupVersion, knownGVRs []schema.GroupVersionResource) (versions int, resources int) {
+	for _, gv := range knownGVs {
+		if o.versionEnabled(gv) {
+			versions++
+		}
+	}
+	for _, gvr := range knownGVRs {
+		if o.ResourceEnabled(gvr) {
+			resources++
+		}
+	}
+	return versions, resources
+}
+
+// EmulationVersionSanity returns advisories when the emulation version filters out an
+// unexpectedly large fraction of the given resources. An emulation version below the
+// introduced version of everything the scheme knows serves nothing, which is almost
+// certainly a misconfiguration rather than an intentional downgrade.
+func (o *ResourceConfig) EmulationVersionSanity(knownGVRs []schema.GroupVersionResource) []string {
+	advisories := []string{}
+	if o.emulationVersion == nil {
+		return advisories
+	}
+	withLifecycle := 0
+	unavailable := 0
+	for _, gvr := range knownGVRs {
+		if _, ok := o.resourceLifecycles[gvr]; !ok {
+			continue
+		}
+		withLifecycle++
+		if !o.apiAvailable(gvr, o.emulationVersion) {
+			unavailable++
+		}
+	}
+	if withLifecycle == 0 {
+		return advisories
+	}
+	switch {
+	case unavailable == withLifecycle:
+		advisories = append(advisories, fmt.Sprintf(
+			"emulation version %s is outside the lifecycle window of all %d known resources with a recorded lifecycle; nothing will be served by default",
+			o.emulationVersion.String(), withLifecycle))
+	case unavailable*2 > withLifecycle:
+		advisories = append(advisories, fmt.Sprintf(
+			"emulation version %s filters out %d of %d known resources with a recorded lifecycle",
+			o.emulationVersion.String(), unavailable, withLifecycle))
+	}
+	return advisories
+}
+
+// IntersectResourceConfigs returns a predicate that is true only when every config enables
+// the resource, for building a lowest-common-denominator config across several apiservers.
+func IntersectResourceConfigs(configs ...APIResourceConfigSource) func(gvr schema.GroupVersionResource) bool {
+	return func(gvr schema.GroupVersionResource) bool {
+		for _, config := range configs {
+			if !config.ResourceEnabled(gvr) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+var (
+	gaVersionPattern    = regexp.MustCompile(`^v\d+$`)
+	betaVersionPattern  = regexp.MustCompile(`^v\d+beta\d+$`)
+	alphaVersionPattern = regexp.MustCompile(`^v\d+alpha\d+$`)
+)
+
+// EnabledVersionsByStability classifies the enabled group/versions by their stability level,
+// keyed by "alpha", "beta" and "ga", based on the version name. Versions that match none of
+// the conventional patterns are omitted. The group/versions in each bucket are sorted.
+func (o *ResourceConfig) EnabledVersionsByStability() map[string][]schema.GroupVersion {
+	buckets := map[string][]schema.GroupVersion{}
+	for groupVersion, enabled := range o.GroupVersionConfigs {
+		if !enabled {
+			continue
+		}
+		switch {
+		case alphaVersionPattern.MatchString(groupVersion.Version):
+			buckets["alpha"] = append(buckets["alpha"], groupVersion)
+		case betaVersionPattern.MatchString(groupVersion.Version):
+			buckets["beta"] = append(buckets["beta"], groupVersion)
+		case gaVersionPattern.MatchString(groupVersion.Version):
+			buckets["ga"] = append(buckets["ga"], groupVersion)
+		}
+	}
+	for _, groupVersions := range buckets {
+		sort.Slice(groupVersions, func(i, j int) bool {
+			return groupVersions[i].String() < groupVersions[j].String()
+		})
+	}
+	return buckets
+}
+
+// PrioritizedVersionsProvider returns the versions of a group in priority order.
+// It is satisfied by runtime.Scheme.
+type PrioritizedVersionsProvider interface {
+	PrioritizedVersionsForGroup(groupName string) []schema.GroupVersion
+}
+
+// EnabledVersionsInPriorityOrder returns the enabled versions of the group in the priority
+// order declared by the provider, as needed by discovery.
+func (o *ResourceConfig) EnabledVersionsInPriorityOrder(group string, provider PrioritizedVersionsProvider) []schema.GroupVersion {
+	enabled := []schema.GroupVersion{}
+	for _, groupVersion := range provider.PrioritizedVersionsForGroup(group) {
+		if o.versionEnabled(groupVersion) {
+			enabled = append(enabled, groupVersion)
+		}
+	}
+	return enabled
+}
+
+// ResourceExpirationEvaluator is the subset of the server resource expiration evaluator
+// needed to decide whether a removed resource would still be served at the current version.
+type ResourceExpirationEvaluator interface {
+	// ShouldServeForVersion returns true if a particular version cut off is after the current version
+	ShouldServeForVersion(majorRemoved, minorRemoved int) bool
+}
+
+// RemovedButEnabled returns the group/versions that are explicitly enabled, but whose resources
+// with a recorded lifecycle have all been removed per the evaluator. These entries will never
+// serve anything, which usually means a stale runtime-config value.
+func (o *ResourceConfig) RemovedButEnabled(evaluator ResourceExpirationEvaluator) []schema.GroupVersion {
+	removed := []schema.GroupVersion{}
+	for groupVersion, enabled := range o.GroupVersionConfigs {
+		if !enabled {
+			continue
+		}
+		lifecyclesSeen := 0
+		allRemoved := true
+		for resource, lifecycle := range o.resourceLifecycles {
+			if resource.GroupVersion() != groupVersion {
+				continue
+			}
+			lifecyclesSeen++
+			if lifecycle.RemovedVersion == nil ||
+				evaluator.ShouldServeForVersion(int(lifecycle.RemovedVersion.Major()), int(lifecycle.RemovedVersion.Minor())) {
+				allRemoved = false
+				break
+			}
+		}
+		if lifecyclesSeen > 0 && allRemoved {
+			removed = append(removed, groupVersion)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].String() < removed[j].String()
+	})
+	return removed
+}
+
+// ResourceConfigData is a serializable snapshot of a ResourceConfig, suitable for persisting
+// an apiserver's API enablement config and reloading it with ImportResourceConfig.
+// Lifecycle windows are not part of the snapshot; explicit preferences and the emulation
+// version are. All slices are sorted for stable output.
+type ResourceConfigData struct {
+	EnabledVersions   []schema.GroupVersion
+	DisabledVersions  []schema.GroupVersion
+	EnabledResources  []schema.GroupVersionResource
+	DisabledResources []schema.GroupVersionResource
+	// EmulationVersion is the emulation version of the config, or empty if it has none.
+	EmulationVersion string
+}
+
+// Export returns a serializable snapshot of the explicit preferences in the config.
+func (o *ResourceConfig) Export() ResourceConfigData {
+	data := ResourceConfigData{}
+	for groupVersion, enabled := range o.GroupVersionConfigs {
+		if enabled {
+			data.EnabledVersions = append(data.EnabledVersions, groupVersion)
+		} else {
+			data.DisabledVersions = append(data.DisabledVersions, groupVersion)
+		}
+	}
+	for resource, enabled := range o.ResourceConfigs {
+		if enabled {
+			data.EnabledResources = append(data.EnabledResources, resource)
+		} else {
+			data.DisabledResources = append(data.DisabledResources, resource)
+		}
+	}
+	sort.Slice(data.EnabledVersions, func(i, j int) bool { return data.EnabledVersions[i].String() < data.EnabledVersions[j].String() })
+	sort.Slice(data.DisabledVersions, func(i, j int) bool { return data.DisabledVersions[i].String() < data.DisabledVersions[j].String() })
+	sort.Slice(data.EnabledResources, func(i, j int) bool { return data.EnabledResources[i].String() < data.EnabledResources[j].String() })
+	sort.Slice(data.DisabledResources, func(i, j int) bool { return data.DisabledResources[i].String() < data.DisabledResources[j].String() })
+	if o.emulationVersion != nil {
+		data.EmulationVersion = o.emulationVersion.String()
+	}
+	return data
+}
+
+// ImportResourceConfig builds a ResourceConfig from a snapshot produced by Export.
+// The imported config gives the same ResourceEnabled answers as the exported one,
+// as long as the lifecycle windows are recorded again by the caller.
+func ImportResourceConfig(data ResourceConfigData) (*ResourceConfig, error) {
+	config := NewResourceConfig()
+	if len(data.EmulationVersion) > 0 {
+		emulationVersion, err := version.ParseGeneric(data.EmulationVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid emulation version %q: %w", data.EmulationVersion, err)
+		}
+		config.emulationVersion = emulationVersion
+	}
+	// apply the version preferences before the resource preferences, since enabling or
+	// disabling a version removes the previously set preferences on its resources.
+	config.EnableVersions(data.EnabledVersions...)
+	config.DisableVersions(data.DisabledVersions...)
+	config.EnableResources(data.EnabledResources...)
+	config.DisableResources(data.DisabledResources...)
+	return config, nil
+}
+
+// ExplicitlyEnabledFutureResources returns the resources that are served only because of an
+// explicit enable, despite a recorded lifecycle introducing them after the emulation version.
+// Operators can use this to see which future resources are being force-served against the
+// emulation version's default.
+func (o *ResourceConfig) ExplicitlyEnabledFutureResources() []schema.GroupVersionResource {
+	future := []schema.GroupVersionResource{}
+	if o.emulationVersion == nil {
+		return future
+	}
+	for resource, enabled := range o.ResourceConfigs {
+		if !enabled {
+			continue
+		}
+		lifecycle, ok := o.resourceLifecycles[resource]
+		if !ok || lifecycle.IntroducedVersion == nil {
+			continue
+		}
+		if o.emulationVersion.LessThan(lifecycle.IntroducedVersion) {
+			future = append(future, resource)
+		}
+	}
+	sort.Slice(future, func(i, j int) bool {
+		return future[i].String() < future[j].String()
+	})
+	return future
+}
+
+// ValidateAgainstScheme checks that every explicitly enabled resource has a registered kind,
+// per the given RESTMapper-like lookup function. Enabling a resource whose kind is not
+// registered in the scheme silently never serves, so it is almost always a misconfiguration.
+func (o *ResourceConfig) ValidateAgainstScheme(kindsFor func(resource schema.GroupVersionResource) ([]schema.GroupVersionKind, error)) []error {
+	enabled := []schema.GroupVersionResource{}
+	for resource, resourceEnabled := range o.ResourceConfigs {
+		if resourceEnabled {
+			enabled = append(enabled, resource)
+		}
+	}
+	sort.Slice(enabled, func(i, j int) bool {
+		return enabled[i].String() < enabled[j].String()
+	})
+	errs := []error{}
+	for _, resource := range enabled {
+		kinds, err := kindsFor(resource)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resource %v is enabled but its kind could not be resolved: %v", resource, err))
+			continue
+		}
+		if len(kinds) == 0 {
+			errs = append(errs, fmt.Errorf("resource %v is enabled but has no kind registered in the scheme", resource))
+		}
+	}
+	return errs
+}
+
+// ValidateSchemeLifecycles cross-checks the recorded resource lifecycles against the
+// lifecycle windows of their group/versions, per the given scheme-backed lookup. The lookup
+// returns the lifecycle of a group/version and whether one is recorded. A resource
+// introduced before its group/version, or removed after it, is a scheme-registration bug
+// that is better caught at startup than discovered through a resource mysteriously not
+// serving. The errors are sorted by resource.
+func (o *ResourceConfig) ValidateSchemeLifecycles(groupVersionLifecycleFor func(gv schema.GroupVersion) (ResourceLifecycle, bool)) []error {
+	resources := make([]schema.GroupVersionResource, 0, len(o.resourceLifecycles))
+	for resource := range o.resourceLifecycles {
+		resources = append(resources, resource)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].String() < resources[j].String()
+	})
+	errs := []error{}
+	for _, resource := range resources {
+		groupVersionLifecycle, ok := groupVersionLifecycleFor(resource.GroupVersion())
+		if !ok {
+			continue
+		}
+		lifecycle := o.resourceLifecycles[resource]
+		if lifecycle.IntroducedVersion != nil && groupVersionLifecycle.IntroducedVersion != nil &&
+			lifecycle.IntroducedVersion.LessThan(groupVersionLifecycle.IntroducedVersion) {
+			errs = append(errs, fmt.Errorf("resource %v introduced at %s before its group/version %v is introduced at %s",
+				resource, lifecycle.IntroducedVersion.String(), resource.GroupVersion(), groupVersionLifecycle.IntroducedVersion.String()))
+		}
+		if lifecycle.RemovedVersion != nil && groupVersionLifecycle.RemovedVersion != nil &&
+			lifecycle.RemovedVersion.GreaterThan(groupVersionLifecycle.RemovedVersion) {
+			errs = append(errs, fmt.Errorf("resource %v removed at %s after its group/version %v is removed at %s",
+				resource, lifecycle.RemovedVersion.String(), resource.GroupVersion(), groupVersionLifecycle.RemovedVersion.String()))
+		}
+	}
+	return errs
+}
+
+// EnabledDeprecatedVersions returns the explicitly enabled group/versions that are deprecated
+// at the emulation version, per the given scheme-backed lookup. The lookup returns the version
+// a group/version is deprecated at, or nil when it is not deprecated. This drives a
+// "you have deprecated APIs enabled" warning. The result is sorted. Without an emulation
+// version, nothing is reported.
+func (o *ResourceConfig) EnabledDeprecatedVersions(deprecatedVersionFor func(gv schema.GroupVersion) *version.Version) []schema.GroupVersion {
+	deprecated := []schema.GroupVersion{}
+	if o.emulationVersion == nil {
+		return deprecated
+	}
+	for groupVersion, enabled := range o.GroupVersionConfigs {
+		if !enabled {
+			continue
+		}
+		deprecatedVersion := deprecatedVersionFor(groupVersion)
+		if deprecatedVersion != nil && o.emulationVersion.AtLeast(deprecatedVersion) {
+			deprecated = append(deprecated, groupVersion)
+		}
+	}
+	sort.Slice(deprecated, func(i, j int) bool {
+		return deprecated[i].String() < deprecated[j].String()
+	})
+	return deprecated
+}
+
+// ResourceEnablementRow describes how the enablement of one resource is derived, for a
+// debug endpoint dumping the full enablement matrix of a config.
+type ResourceEnablementRow struct {
+	Resource schema.GroupVersionResource
+	// GroupVersionEnabled is the enablement of the resource's group/version.
+	GroupVersionEnabled bool
+	// ResourceExplicitlyEnabled and ResourceExplicitlySet describe the explicit preference on
+	// the resource itself: unspecified when ResourceExplicitlySet is false.
+	ResourceExplicitlyEnabled bool
+	ResourceExplicitlySet     bool
+	// Lifecycle is the recorded lifecycle window of the resource, zero when none is recorded.
+	Lifecycle ResourceLifecycle
+	// Enabled is the final effective enablement, as answered by ResourceEnabled.
+	Enabled bool
+}
+
+// EnablementMatrix returns one row per known resource describing how its enablement is
+// derived: the group/version preference, the explicit resource preference, the lifecycle
+// window and the final answer. Rows are sorted by resource.
+func (o *ResourceConfig) EnablementMatrix(knownGVRs []schema.GroupVersionResource) []ResourceEnablementRow {
+	rows := make([]ResourceEnablementRow, 0, len(knownGVRs))
+	for _, gvr := range knownGVRs {
+		enabled, explicitlySet := o.ResourceConfigs[gvr]
+		rows = append(rows, ResourceEnablementRow{
+			Resource:                  gvr,
+			GroupVersionEnabled:       o.versionEnabled(gvr.GroupVersion()),
+			ResourceExplicitlyEnabled: enabled,
+			ResourceExplicitlySet:     explicitlySet,
+			Lifecycle:                 o.resourceLifecycles[gvr],
+			Enabled:                   o.ResourceEnabled(gvr),
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Resource.String() < rows[j].Resource.String()
+	})
+	return rows
+}
+
+// resourceOpinionSource is the optional three-valued lookup a layer of a layered source can
+// implement to report whether it has an explicit opinion, instead of defaulting to disabled.
+type resourceOpinionSource interface {
+	// resourceOpinion returns the enablement of the resource and whether the source has an
+	// explicit opinion on it, either directly or through its group/version.
+	resourceOpinion(resource schema.GroupVersionResource) (enabled, ok bool)
+	// versionOpinion returns the enablement of the group/version and whether the source has
+	// an explicit opinion on it.
+	versionOpinion(gv schema.GroupVersion) (enabled, ok bool)
+}
+
+var _ resourceOpinionSource = &ResourceConfig{}
+
+func (o *ResourceConfig) resourceOpinion(resource schema.GroupVersionResource) (bool, bool) {
+	// an explicit resource preference takes priority over the preference of the version.
+	if enabled, ok := o.ResourceConfigs[resource]; ok {
+		return enabled, true
+	}
+	if enabled, ok := o.GroupVersionConfigs[resource.GroupVersion()]; ok {
+		if !enabled || o.versionDefaultDeny[resource.GroupVersion()] {
+			return false, true
+		}
+		return o.apiAvailable(resource, o.emulationVersion), true
+	}
+	return false, false
+}
+
+func (o *ResourceConfig) versionOpinion(gv schema.GroupVersion) (bool, bool) {
+	enabled, ok := o.GroupVersionConfigs[gv]
+	return enabled, ok
+}
+
+// NewLayeredResourceConfigSource returns a source that overlays the given layers lazily:
+// a lookup consults the layers in order and answers with the first layer that has an
+// explicit opinion, so later changes to a layer are visible through the overlay.
+// A layer that does not support the three-valued lookup answers for everything, shadowing
+// the layers below it. With no opinion anywhere, the resource is disabled.
+func NewLayeredResourceConfigSource(layers ...APIResourceConfigSource) APIResourceConfigSource {
+	return &layeredResourceConfigSource{layers: layers}
+}
+
+type layeredResourceConfigSource struct {
+	layers []APIResourceConfigSource
+}
+
+var _ APIResourceConfigSource = &layeredResourceConfigSource{}
+
+func (c *layeredResourceConfigSource) ResourceEnabled(resource schema.GroupVersionResource) bool {
+	for _, layer := range c.layers {
+		opinionated, ok := layer.(resourceOpinionSource)
+		if !ok {
+			return layer.ResourceEnabled(resource)
+		}
+		if enabled, ok := opinionated.resourceOpinion(resource); ok {
+			return enabled
+		}
+	}
+	return false
+}
+
+func (c *layeredResourceConfigSource) versionEnabled(gv schema.GroupVersion) bool {
+	for _, layer := range c.layers {
+		// a layer without the three-valued lookup has no per-version answer to give.
+		if opinionated, ok := layer.(resourceOpinionSource); ok {
+			if enabled, ok := opinionated.versionOpinion(gv); ok {
+				return enabled
+			}
+		}
+	}
+	return false
+}
+
+func (c *layeredResourceConfigSource) AnyResourceForGroupEnabled(group string) bool {
+	for _, layer := range c.layers {
+		config, ok := layer.(*ResourceConfig)
+		if !ok {
+			return layer.AnyResourceForGroupEnabled(group)
+		}
+		// the entries of the layer are only candidates; the layers above may override them,
+		// so each one is answered through the overlay.
+		for groupVersion := range config.GroupVersionConfigs {
+			if groupVersion.Group == group && c.versionEnabled(groupVersion) {
+				return true
+			}
+		}
+		for resource := range config.ResourceConfigs {
+			if resource.Group == group && c.ResourceEnabled(resource) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (o *ResourceConfig) AnyResourceForGroupEnabled(group string) bool {
-	for version := range o.GroupVersionConfigs {
-		if version.Group == group {
-			if o.versionEnabled(version) {
+	return o.anyResourceForGroupEnabledAtEmulationVersion(group, o.emulationVersion)
+}
+
+func (o *ResourceConfig) anyResourceForGroupEnabledAtEmulationVersion(group string, v *version.Version) bool {
+	for groupVersion := range o.GroupVersionConfigs {
+		if groupVersion.Group == group {
+			if o.versionEnabled(groupVersion) {
 				return true
 			}
 		}
 	}
 	for resource := range o.ResourceConfigs {
-		if resource.Group == group && o.ResourceEnabled(resource) {
+		if resource.Group == group && o.resourceEnabledAtEmulationVersion(resource, v) {
 			return true
 		}
 	}
@@ -17,9 +17,16 @@ limitations under the License.
 package storage
 
 import (
+	"bytes"
+	"flag"
+	"reflect"
+	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/klog/v2"
 )
 
 func TestDisabledVersion(t *testing.T) {
@@ -102,6 +109,71 @@ func TestDisabledResource(t *testing.T) {
 	}
 }
 
+func TestEnabledResourcesForVersion(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+	g1v1rUnspecified := g1v1.WithResource("unspecified")
+	g1v1rEnabled := g1v1.WithResource("enabled")
+	g1v1rDisabled := g1v1.WithResource("disabled")
+	g1v2rOtherVersion := schema.GroupVersion{Group: "group1", Version: "version2"}.WithResource("other")
+
+	config := NewResourceConfig()
+
+	config.EnableVersions(g1v1)
+	config.EnableResources(g1v1rEnabled)
+	config.DisableResources(g1v1rDisabled)
+
+	candidates := []schema.GroupVersionResource{g1v1rUnspecified, g1v1rEnabled, g1v1rDisabled, g1v2rOtherVersion}
+	expected := []schema.GroupVersionResource{g1v1rUnspecified, g1v1rEnabled}
+	if actual := config.EnabledResourcesForVersion(g1v1, candidates); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	// all candidates are excluded once the version is disabled and the explicit preferences are cleared.
+	config.DisableVersions(g1v1)
+	if actual := config.EnabledResourcesForVersion(g1v1, candidates); len(actual) != 0 {
+		t.Errorf("expected no enabled resources, got %v", actual)
+	}
+}
+
+func TestAtEmulationVersion(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+	g1v1rFuture := g1v1.WithResource("future")
+	g1v1rCurrent := g1v1.WithResource("current")
+	g1v1rDisabled := g1v1.WithResource("disabled")
+
+	config := NewResourceConfig()
+	config.EnableVersions(g1v1)
+	config.DisableResources(g1v1rDisabled)
+	config.resourceLifecycles[g1v1rFuture] = ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 25)}
+	config.resourceLifecycles[g1v1rCurrent] = ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 18)}
+
+	scoped := config.AtEmulationVersion(utilversion.MajorMinor(1, 20))
+
+	// the parent has no emulation version, so lifecycle windows do not apply to it.
+	if !config.ResourceEnabled(g1v1rFuture) {
+		t.Errorf("expected enabled for %v, from %v", g1v1rFuture, config)
+	}
+	// the scoped view evaluates availability at 1.20, before the resource is introduced.
+	if scoped.ResourceEnabled(g1v1rFuture) {
+		t.Errorf("expected disabled for %v at emulation version 1.20", g1v1rFuture)
+	}
+	if !scoped.ResourceEnabled(g1v1rCurrent) {
+		t.Errorf("expected enabled for %v at emulation version 1.20", g1v1rCurrent)
+	}
+
+	// explicit preferences are shared with the parent.
+	if scoped.ResourceEnabled(g1v1rDisabled) {
+		t.Errorf("expected disabled for %v at emulation version 1.20", g1v1rDisabled)
+	}
+	config.EnableResources(g1v1rDisabled)
+	if !scoped.ResourceEnabled(g1v1rDisabled) {
+		t.Errorf("expected the scoped view to observe the parent's explicit enablement of %v", g1v1rDisabled)
+	}
+	if !scoped.AnyResourceForGroupEnabled("group1") {
+		t.Errorf("expected group1 to be enabled at emulation version 1.20")
+	}
+}
+
 func TestAnyVersionForGroupEnabled(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -176,3 +248,731 @@ func TestAnyVersionForGroupEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestSourceFor(t *testing.T) {
+	gv := schema.GroupVersion{Group: "one", Version: "version1"}
+
+	config := NewResourceConfig()
+	config.EnableVersionsFromSource("defaults", gv)
+	if e, a := "defaults", config.SourceFor(gv); e != a {
+		t.Errorf("expected source %q, got %q", e, a)
+	}
+	if !config.versionEnabled(gv) {
+		t.Errorf("expected %v to be enabled", gv)
+	}
+
+	// re-setting with a different source overwrites the recorded source.
+	config.DisableVersionsFromSource("flags", gv)
+	if e, a := "flags", config.SourceFor(gv); e != a {
+		t.Errorf("expected source %q, got %q", e, a)
+	}
+	if config.versionEnabled(gv) {
+		t.Errorf("expected %v to be disabled", gv)
+	}
+
+	// the default methods clear any previously recorded source.
+	config.EnableVersions(gv)
+	if e, a := "", config.SourceFor(gv); e != a {
+		t.Errorf("expected source %q, got %q", e, a)
+	}
+}
+
+// fixedVersionEvaluator serves anything removed after the current version.
+type fixedVersionEvaluator struct {
+	currentVersion *utilversion.Version
+}
+
+func (e fixedVersionEvaluator) ShouldServeForVersion(majorRemoved, minorRemoved int) bool {
+	return utilversion.MajorMinor(uint(majorRemoved), uint(minorRemoved)).GreaterThan(e.currentVersion)
+}
+
+func TestRemovedButEnabled(t *testing.T) {
+	removedGV := schema.GroupVersion{Group: "one", Version: "v1beta1"}
+	servingGV := schema.GroupVersion{Group: "one", Version: "v1"}
+	noLifecycleGV := schema.GroupVersion{Group: "two", Version: "v1"}
+
+	config := NewResourceConfig()
+	config.EnableVersions(removedGV, servingGV, noLifecycleGV)
+	config.resourceLifecycles[removedGV.WithResource("foos")] = ResourceLifecycle{
+		RemovedVersion: utilversion.MajorMinor(1, 20),
+	}
+	config.resourceLifecycles[servingGV.WithResource("foos")] = ResourceLifecycle{
+		IntroducedVersion: utilversion.MajorMinor(1, 19),
+	}
+
+	evaluator := fixedVersionEvaluator{currentVersion: utilversion.MajorMinor(1, 22)}
+	actual := config.RemovedButEnabled(evaluator)
+	expected := []schema.GroupVersion{removedGV}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	// nothing is reported before the removal version.
+	evaluator = fixedVersionEvaluator{currentVersion: utilversion.MajorMinor(1, 19)}
+	if actual := config.RemovedButEnabled(evaluator); len(actual) != 0 {
+		t.Errorf("expected no removed group/versions, got %v", actual)
+	}
+}
+
+func TestEnabledVersionsByStability(t *testing.T) {
+	alphaGV := schema.GroupVersion{Group: "one", Version: "v1alpha1"}
+	betaGV := schema.GroupVersion{Group: "one", Version: "v1beta2"}
+	gaGV := schema.GroupVersion{Group: "two", Version: "v1"}
+	disabledGV := schema.GroupVersion{Group: "two", Version: "v2alpha1"}
+	unconventionalGV := schema.GroupVersion{Group: "three", Version: "version1"}
+
+	config := NewResourceConfig()
+	config.EnableVersions(alphaGV, betaGV, gaGV, unconventionalGV)
+	config.DisableVersions(disabledGV)
+
+	expected := map[string][]schema.GroupVersion{
+		"alpha": {alphaGV},
+		"beta":  {betaGV},
+		"ga":    {gaGV},
+	}
+	if actual := config.EnabledVersionsByStability(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestEnableIntroducedUpTo(t *testing.T) {
+	gv := schema.GroupVersion{Group: "one", Version: "v1"}
+	in29 := gv.WithResource("twentynines")
+	in30 := gv.WithResource("thirties")
+	in31 := gv.WithResource("thirtyones")
+
+	config := NewResourceConfig()
+	config.resourceLifecycles[in29] = ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 29)}
+	config.resourceLifecycles[in30] = ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 30)}
+	config.resourceLifecycles[in31] = ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 31)}
+
+	config.EnableIntroducedUpTo(utilversion.MajorMinor(1, 30), []schema.GroupVersionResource{in29, in30, in31})
+
+	for _, enabled := range []schema.GroupVersionResource{in29, in30} {
+		if !config.ResourceEnabled(enabled) {
+			t.Errorf("expected %v to be enabled", enabled)
+		}
+	}
+	if config.ResourceEnabled(in31) {
+		t.Errorf("expected %v to be left untouched", in31)
+	}
+	if _, explicitlySet := config.ResourceConfigs[in31]; explicitlySet {
+		t.Errorf("expected no explicit preference for %v", in31)
+	}
+}
+
+func TestNewResourceConfigWith(t *testing.T) {
+	gv := schema.GroupVersion{Group: "one", Version: "v1"}
+
+	config, err := NewResourceConfigWith(func(c *ResourceConfig) {
+		c.EnableVersions(gv)
+		c.resourceLifecycles[gv.WithResource("foos")] = ResourceLifecycle{
+			IntroducedVersion: utilversion.MajorMinor(1, 25),
+			RemovedVersion:    utilversion.MajorMinor(1, 30),
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !config.versionEnabled(gv) {
+		t.Errorf("expected %v to be enabled", gv)
+	}
+
+	if _, err := NewResourceConfigWith(func(c *ResourceConfig) {
+		c.resourceLifecycles[gv.WithResource("foos")] = ResourceLifecycle{
+			IntroducedVersion: utilversion.MajorMinor(1, 30),
+			RemovedVersion:    utilversion.MajorMinor(1, 25),
+		}
+	}); err == nil {
+		t.Fatal("expected error for an inverted lifecycle window, got none")
+	}
+}
+
+type fakePrioritizedVersionsProvider map[string][]schema.GroupVersion
+
+func (f fakePrioritizedVersionsProvider) PrioritizedVersionsForGroup(groupName string) []schema.GroupVersion {
+	return f[groupName]
+}
+
+func TestEnabledVersionsInPriorityOrder(t *testing.T) {
+	v1 := schema.GroupVersion{Group: "one", Version: "v1"}
+	v1beta1 := schema.GroupVersion{Group: "one", Version: "v1beta1"}
+	v1alpha1 := schema.GroupVersion{Group: "one", Version: "v1alpha1"}
+	provider := fakePrioritizedVersionsProvider{
+		"one": {v1, v1beta1, v1alpha1},
+	}
+
+	config := NewResourceConfig()
+	config.EnableVersions(v1, v1alpha1)
+	config.DisableVersions(v1beta1)
+
+	expected := []schema.GroupVersion{v1, v1alpha1}
+	if actual := config.EnabledVersionsInPriorityOrder("one", provider); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if actual := config.EnabledVersionsInPriorityOrder("other", provider); len(actual) != 0 {
+		t.Errorf("expected no enabled versions for an unknown group, got %v", actual)
+	}
+}
+
+func TestExplicitlyEnabledFutureResources(t *testing.T) {
+	gv := schema.GroupVersion{Group: "one", Version: "v1"}
+	futureResource := gv.WithResource("futures")
+	currentResource := gv.WithResource("currents")
+	disabledFutureResource := gv.WithResource("disabledfutures")
+
+	config := NewResourceConfig()
+	config.emulationVersion = utilversion.MajorMinor(1, 31)
+	config.EnableVersions(gv)
+	config.EnableResources(futureResource, currentResource)
+	config.DisableResources(disabledFutureResource)
+	config.SetResourceLifecycle(futureResource, ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 33)})
+	config.SetResourceLifecycle(currentResource, ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 30)})
+	config.SetResourceLifecycle(disabledFutureResource, ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 33)})
+
+	// the explicit enable keeps the future resource served despite its introduced version.
+	if !config.ResourceEnabled(futureResource) {
+		t.Fatalf("expected explicitly enabled future resource to be served")
+	}
+	expected := []schema.GroupVersionResource{futureResource}
+	if actual := config.ExplicitlyEnabledFutureResources(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("ExplicitlyEnabledFutureResources() = %v, want %v", actual, expected)
+	}
+
+	// without an emulation version, nothing is reported.
+	config.emulationVersion = nil
+	if actual := config.ExplicitlyEnabledFutureResources(); len(actual) != 0 {
+		t.Errorf("expected no future resources without an emulation version, got %v", actual)
+	}
+}
+
+func TestExportImportResourceConfig(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+	g1v2 := schema.GroupVersion{Group: "group1", Version: "version2"}
+	g2v1 := schema.GroupVersion{Group: "group2", Version: "version1"}
+
+	// the same configuration as TestDisabledResource.
+	config := NewResourceConfig()
+	config.DisableVersions(g1v1)
+	config.EnableVersions(g1v2, g2v1)
+	config.EnableResources(g1v1.WithResource("enabled"), g1v2.WithResource("enabled"), g2v1.WithResource("enabled"))
+	config.DisableResources(g1v1.WithResource("disabled"), g1v2.WithResource("disabled"), g2v1.WithResource("disabled"))
+
+	imported, err := ImportResourceConfig(config.Export())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, gv := range []schema.GroupVersion{g1v1, g1v2, g2v1} {
+		for _, resourceName := range []string{"unspecified", "enabled", "disabled"} {
+			gvr := gv.WithResource(resourceName)
+			if expected, actual := config.ResourceEnabled(gvr), imported.ResourceEnabled(gvr); expected != actual {
+				t.Errorf("imported config: ResourceEnabled(%v) = %v, want %v", gvr, actual, expected)
+			}
+		}
+	}
+
+	// exporting the imported config round-trips to the same snapshot.
+	if expected, actual := config.Export(), imported.Export(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("round-tripped export = %#v, want %#v", actual, expected)
+	}
+
+	// an unparseable emulation version is rejected.
+	if _, err := ImportResourceConfig(ResourceConfigData{EmulationVersion: "not-a-version"}); err == nil {
+		t.Error("expected error importing an invalid emulation version, got none")
+	}
+}
+
+func TestValidateAgainstScheme(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+	validResource := g1v1.WithResource("resource1")
+	bogusResource := g1v1.WithResource("bogus")
+
+	config := NewResourceConfig()
+	config.EnableVersions(g1v1)
+	config.EnableResources(validResource, bogusResource)
+
+	kindsFor := func(resource schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+		if resource == validResource {
+			return []schema.GroupVersionKind{g1v1.WithKind("Resource1")}, nil
+		}
+		return nil, nil
+	}
+
+	errs := config.ValidateAgainstScheme(kindsFor)
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly one error, got %v", errs)
+	}
+	expectedError := "resource group1/version1, Resource=bogus is enabled but has no kind registered in the scheme"
+	if errs[0].Error() != expectedError {
+		t.Errorf("expected error %q, got %q", expectedError, errs[0].Error())
+	}
+}
+
+func TestClearResource(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+	g1v1rsc1 := g1v1.WithResource("resource1")
+
+	config := NewResourceConfig()
+	config.DisableVersions(g1v1)
+	config.EnableResources(g1v1rsc1)
+	if !config.ResourceEnabled(g1v1rsc1) {
+		t.Errorf("expected enabled for %v (explicitly enabled)", g1v1rsc1)
+	}
+
+	config.ClearResource(g1v1rsc1)
+	if config.ResourceEnabled(g1v1rsc1) {
+		t.Errorf("expected disabled for %v (version disabled, no explicit preference)", g1v1rsc1)
+	}
+	// clearing a resource that has no explicit preference is a no-op.
+	config.ClearResource(g1v1.WithResource("resource2"))
+}
+
+func TestClearVersion(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+
+	config := NewResourceConfig()
+	config.DisableVersionsFromSource("runtime-config", g1v1)
+	if _, explicitlySet := config.GroupVersionConfigs[g1v1]; !explicitlySet {
+		t.Fatalf("expected explicit decision for %v", g1v1)
+	}
+
+	config.ClearVersion(g1v1)
+	if _, explicitlySet := config.GroupVersionConfigs[g1v1]; explicitlySet {
+		t.Errorf("expected no explicit decision for %v after clearing", g1v1)
+	}
+	if source := config.SourceFor(g1v1); source != "" {
+		t.Errorf("expected no source for %v after clearing, got %q", g1v1, source)
+	}
+	// an unmentioned group/version falls back to the global default, disabled.
+	if config.versionEnabled(g1v1) {
+		t.Errorf("expected disabled for %v after clearing", g1v1)
+	}
+}
+
+func TestEnableDisableResourcesChecked(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+	knownResource := g1v1.WithResource("resource1")
+	unknownResource := g1v1.WithResource("resourcetypo")
+
+	kindsFor := func(resource schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+		if resource == knownResource {
+			return []schema.GroupVersionKind{g1v1.WithKind("Resource1")}, nil
+		}
+		return nil, nil
+	}
+
+	config := NewResourceConfig()
+	rejected := config.EnableResourcesChecked(kindsFor, knownResource, unknownResource)
+	if expected := []schema.GroupVersionResource{unknownResource}; !reflect.DeepEqual(rejected, expected) {
+		t.Errorf("expected rejected %v, got %v", expected, rejected)
+	}
+	if !config.ResourceEnabled(knownResource) {
+		t.Errorf("expected enabled for %v", knownResource)
+	}
+	if _, explicitlySet := config.ResourceConfigs[unknownResource]; explicitlySet {
+		t.Errorf("expected no explicit preference for rejected %v", unknownResource)
+	}
+
+	rejected = config.DisableResourcesChecked(kindsFor, knownResource, unknownResource)
+	if expected := []schema.GroupVersionResource{unknownResource}; !reflect.DeepEqual(rejected, expected) {
+		t.Errorf("expected rejected %v, got %v", expected, rejected)
+	}
+	if config.ResourceEnabled(knownResource) {
+		t.Errorf("expected disabled for %v", knownResource)
+	}
+}
+
+func TestIntersectResourceConfigs(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+	commonResource := g1v1.WithResource("resource1")
+	contestedResource := g1v1.WithResource("resource2")
+
+	configA := NewResourceConfig()
+	configA.EnableVersions(g1v1)
+
+	configB := NewResourceConfig()
+	configB.EnableVersions(g1v1)
+	configB.DisableResources(contestedResource)
+
+	enabledEverywhere := IntersectResourceConfigs(configA, configB)
+	if !enabledEverywhere(commonResource) {
+		t.Errorf("expected %v to be enabled in the intersection", commonResource)
+	}
+	if enabledEverywhere(contestedResource) {
+		t.Errorf("expected %v to be excluded from the intersection", contestedResource)
+	}
+}
+
+func TestEmulationVersionSanity(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+	g1v1rsc1 := g1v1.WithResource("resource1")
+	g1v1rsc2 := g1v1.WithResource("resource2")
+	knownGVRs := []schema.GroupVersionResource{g1v1rsc1, g1v1rsc2}
+
+	config := NewResourceConfig()
+	config.EnableVersions(g1v1)
+	config.SetResourceLifecycle(g1v1rsc1, ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 30)})
+	config.SetResourceLifecycle(g1v1rsc2, ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 31)})
+
+	// no emulation version, nothing to check.
+	if advisories := config.EmulationVersionSanity(knownGVRs); len(advisories) != 0 {
+		t.Errorf("expected no advisories without an emulation version, got %v", advisories)
+	}
+
+	// emulation far below every introduced version.
+	config.emulationVersion = utilversion.MajorMinor(1, 25)
+	advisories := config.EmulationVersionSanity(knownGVRs)
+	if len(advisories) != 1 || !strings.Contains(advisories[0], "nothing will be served by default") {
+		t.Errorf("expected an advisory that nothing will be served, got %v", advisories)
+	}
+
+	// emulation covering every lifecycle window.
+	config.emulationVersion = utilversion.MajorMinor(1, 31)
+	if advisories := config.EmulationVersionSanity(knownGVRs); len(advisories) != 0 {
+		t.Errorf("expected no advisories, got %v", advisories)
+	}
+}
+
+func TestEmulationVersionGetter(t *testing.T) {
+	config := NewResourceConfig()
+	if actual := config.EmulationVersion(); actual != nil {
+		t.Errorf("expected nil emulation version for a fresh config, got %v", actual)
+	}
+
+	imported, err := ImportResourceConfig(ResourceConfigData{EmulationVersion: "1.31"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := imported.EmulationVersion(); actual == nil || !actual.EqualTo(utilversion.MajorMinor(1, 31)) {
+		t.Errorf("expected emulation version 1.31, got %v", actual)
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	gv := schema.GroupVersion{Group: "group1", Version: "version1"}
+
+	config := NewResourceConfig()
+	if !config.IsEmpty() {
+		t.Errorf("expected a fresh config to be empty")
+	}
+
+	versionOnly := NewResourceConfig()
+	versionOnly.DisableVersions(gv)
+	if versionOnly.IsEmpty() {
+		t.Errorf("expected a config with a version preference to not be empty")
+	}
+
+	resourceOnly := NewResourceConfig()
+	resourceOnly.DisableResources(gv.WithResource("resource1"))
+	if resourceOnly.IsEmpty() {
+		t.Errorf("expected a config with a resource preference to not be empty")
+	}
+}
+
+func TestLayeredResourceConfigSource(t *testing.T) {
+	gv := schema.GroupVersion{Group: "one", Version: "v1"}
+	overriddenResource := gv.WithResource("overriddens")
+	lowerOnlyResource := gv.WithResource("lowers")
+	unknownResource := gv.WithResource("unknowns")
+
+	lower := NewResourceConfig()
+	lower.EnableVersions(gv)
+	lower.EnableResources(overriddenResource, lowerOnlyResource)
+
+	upper := NewResourceConfig()
+	upper.DisableResources(overriddenResource)
+
+	layered := NewLayeredResourceConfigSource(upper, lower)
+
+	// the upper layer has an opinion on the resource and overrides the lower one.
+	if layered.ResourceEnabled(overriddenResource) {
+		t.Errorf("expected the upper layer to disable %v", overriddenResource)
+	}
+	// only the lower layer has an opinion.
+	if !layered.ResourceEnabled(lowerOnlyResource) {
+		t.Errorf("expected the lower layer to enable %v", lowerOnlyResource)
+	}
+	if !layered.AnyResourceForGroupEnabled(gv.Group) {
+		t.Errorf("expected group %v to have an enabled resource", gv.Group)
+	}
+
+	// without an opinion in any layer, the resource is disabled.
+	noOpinion := NewLayeredResourceConfigSource(NewResourceConfig(), NewResourceConfig())
+	if noOpinion.ResourceEnabled(unknownResource) {
+		t.Errorf("expected %v to be disabled without an opinion in any layer", unknownResource)
+	}
+	if noOpinion.AnyResourceForGroupEnabled(gv.Group) {
+		t.Errorf("expected no enabled resources for group %v without an opinion in any layer", gv.Group)
+	}
+
+	// the overlay is live: enabling the resource in the upper layer later is visible.
+	upper.EnableResources(overriddenResource)
+	if !layered.ResourceEnabled(overriddenResource) {
+		t.Errorf("expected the later upper-layer enable of %v to be visible", overriddenResource)
+	}
+}
+
+func TestEnablementMatrix(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+	g1v1rEnabled := g1v1.WithResource("enabled")
+	g1v2 := schema.GroupVersion{Group: "group1", Version: "version2"}
+	g1v2rUnspecified := g1v2.WithResource("unspecified")
+	g1v2rDisabled := g1v2.WithResource("disabled")
+	g1v2rFuture := g1v2.WithResource("future")
+
+	config := NewResourceConfig()
+	config.emulationVersion = utilversion.MajorMinor(1, 31)
+	config.DisableVersions(g1v1)
+	config.EnableVersions(g1v2)
+	config.EnableResources(g1v1rEnabled)
+	config.DisableResources(g1v2rDisabled)
+	config.SetResourceLifecycle(g1v2rFuture, ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 33)})
+
+	actual := config.EnablementMatrix([]schema.GroupVersionResource{
+		g1v2rUnspecified, g1v1rEnabled, g1v2rDisabled, g1v2rFuture,
+	})
+	expected := []ResourceEnablementRow{
+		{
+			Resource:                  g1v1rEnabled,
+			GroupVersionEnabled:       false,
+			ResourceExplicitlyEnabled: true,
+			ResourceExplicitlySet:     true,
+			Enabled:                   true,
+		},
+		{
+			Resource:                  g1v2rDisabled,
+			GroupVersionEnabled:       true,
+			ResourceExplicitlyEnabled: false,
+			ResourceExplicitlySet:     true,
+			Enabled:                   false,
+		},
+		{
+			Resource:            g1v2rFuture,
+			GroupVersionEnabled: true,
+			Lifecycle:           ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 33)},
+			Enabled:             false,
+		},
+		{
+			Resource:            g1v2rUnspecified,
+			GroupVersionEnabled: true,
+			Enabled:             true,
+		},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("EnablementMatrix() = %+v, want %+v", actual, expected)
+	}
+}
+
+func TestEnabledDeprecatedVersions(t *testing.T) {
+	deprecatedGV := schema.GroupVersion{Group: "one", Version: "v1beta1"}
+	currentGV := schema.GroupVersion{Group: "one", Version: "v1"}
+	disabledDeprecatedGV := schema.GroupVersion{Group: "two", Version: "v1beta1"}
+	notYetDeprecatedGV := schema.GroupVersion{Group: "three", Version: "v1beta1"}
+	deprecatedVersionFor := func(gv schema.GroupVersion) *utilversion.Version {
+		switch gv {
+		case deprecatedGV, disabledDeprecatedGV:
+			return utilversion.MajorMinor(1, 30)
+		case notYetDeprecatedGV:
+			return utilversion.MajorMinor(1, 33)
+		default:
+			return nil
+		}
+	}
+
+	config := NewResourceConfig()
+	config.emulationVersion = utilversion.MajorMinor(1, 31)
+	config.EnableVersions(deprecatedGV, currentGV, notYetDeprecatedGV)
+	config.DisableVersions(disabledDeprecatedGV)
+
+	expected := []schema.GroupVersion{deprecatedGV}
+	if actual := config.EnabledDeprecatedVersions(deprecatedVersionFor); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("EnabledDeprecatedVersions() = %v, want %v", actual, expected)
+	}
+
+	// without an emulation version, nothing is reported.
+	config.emulationVersion = nil
+	if actual := config.EnabledDeprecatedVersions(deprecatedVersionFor); len(actual) != 0 {
+		t.Errorf("expected no deprecated versions without an emulation version, got %v", actual)
+	}
+}
+
+func TestEnableVersionWithOnlyResources(t *testing.T) {
+	gv := schema.GroupVersion{Group: "", Version: "v1"}
+
+	config := NewResourceConfig()
+	config.EnableVersionWithOnlyResources(gv, sets.NewString("pods", "services"))
+
+	if !config.ResourceEnabled(gv.WithResource("pods")) {
+		t.Errorf("expected pods to be enabled")
+	}
+	if !config.ResourceEnabled(gv.WithResource("services")) {
+		t.Errorf("expected services to be enabled")
+	}
+	// unlisted resources default to disabled under the allow-list version.
+	if config.ResourceEnabled(gv.WithResource("events")) {
+		t.Errorf("expected events to be disabled")
+	}
+	if !config.AnyResourceForGroupEnabled(gv.Group) {
+		t.Errorf("expected the group to have an enabled resource")
+	}
+
+	// re-enabling the version normally reverts to default-allow.
+	config.EnableVersions(gv)
+	if !config.ResourceEnabled(gv.WithResource("events")) {
+		t.Errorf("expected events to be enabled after re-enabling the version")
+	}
+}
+
+func TestEnablementChangeLogging(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+	g1v2 := schema.GroupVersion{Group: "group1", Version: "version2"}
+
+	fs := flag.NewFlagSet("test", flag.PanicOnError)
+	klog.InitFlags(fs)
+	if err := fs.Set("v", "5"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := fs.Set("v", "0"); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	var buf bytes.Buffer
+	klog.SetOutput(&buf)
+	klog.LogToStderr(false)
+	defer klog.LogToStderr(true)
+
+	config := NewResourceConfig()
+	config.EnableVersions(g1v1)
+	config.DisableVersions(g1v2)
+	config.EnableResources(g1v1.WithResource("widgets"))
+	config.DisableResources(g1v1.WithResource("gadgets"))
+
+	klog.Flush()
+	klog.SetOutput(&bytes.Buffer{})
+	output := buf.String()
+
+	for _, expected := range []string{
+		"ResourceConfig.EnableVersions(group1/version1) called from ",
+		"ResourceConfig.DisableVersions(group1/version2) called from ",
+		"ResourceConfig.EnableResources(group1/version1, Resource=widgets) called from ",
+		"ResourceConfig.DisableResources(group1/version1, Resource=gadgets) called from ",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected log line containing %q, got:\n%s", expected, output)
+		}
+	}
+	if !strings.Contains(output, "resource_config_test.go") {
+		t.Errorf("expected the caller of the enablement calls in the log, got:\n%s", output)
+	}
+}
+
+func TestValidateSchemeLifecycles(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+	g1v1Lifecycle := ResourceLifecycle{
+		IntroducedVersion: utilversion.MajorMinor(1, 20),
+		RemovedVersion:    utilversion.MajorMinor(1, 30),
+	}
+	groupVersionLifecycleFor := func(gv schema.GroupVersion) (ResourceLifecycle, bool) {
+		if gv == g1v1 {
+			return g1v1Lifecycle, true
+		}
+		return ResourceLifecycle{}, false
+	}
+
+	config := NewResourceConfig()
+	config.SetResourceLifecycle(g1v1.WithResource("consistent"), ResourceLifecycle{
+		IntroducedVersion: utilversion.MajorMinor(1, 22),
+		RemovedVersion:    utilversion.MajorMinor(1, 28),
+	})
+	if errs := config.ValidateSchemeLifecycles(groupVersionLifecycleFor); len(errs) != 0 {
+		t.Errorf("expected no errors for a consistent scheme, got %v", errs)
+	}
+
+	config.SetResourceLifecycle(g1v1.WithResource("early"), ResourceLifecycle{
+		IntroducedVersion: utilversion.MajorMinor(1, 18),
+	})
+	config.SetResourceLifecycle(g1v1.WithResource("late"), ResourceLifecycle{
+		RemovedVersion: utilversion.MajorMinor(1, 32),
+	})
+	// a resource of a group/version without a recorded lifecycle is not checked.
+	config.SetResourceLifecycle(schema.GroupVersion{Group: "group2", Version: "version1"}.WithResource("unknowns"), ResourceLifecycle{
+		IntroducedVersion: utilversion.MajorMinor(1, 1),
+	})
+
+	errs := config.ValidateSchemeLifecycles(groupVersionLifecycleFor)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if expected := "resource group1/version1, Resource=early introduced at 1.18 before its group/version group1/version1 is introduced at 1.20"; errs[0].Error() != expected {
+		t.Errorf("expected %q, got %q", expected, errs[0].Error())
+	}
+	if expected := "resource group1/version1, Resource=late removed at 1.32 after its group/version group1/version1 is removed at 1.30"; errs[1].Error() != expected {
+		t.Errorf("expected %q, got %q", expected, errs[1].Error())
+	}
+}
+
+func TestSetResourceEmulationOverride(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+	g1v1rPinned := g1v1.WithResource("pinned")
+	g1v1rFuture := g1v1.WithResource("future")
+
+	config := NewResourceConfig()
+	config.EnableVersions(g1v1)
+	config.emulationVersion = utilversion.MajorMinor(1, 25)
+	config.SetResourceLifecycle(g1v1rPinned, ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 28)})
+	config.SetResourceLifecycle(g1v1rFuture, ResourceLifecycle{IntroducedVersion: utilversion.MajorMinor(1, 28)})
+
+	// both resources are introduced after the emulation version of the config.
+	if config.ResourceEnabled(g1v1rPinned) {
+		t.Errorf("expected disabled for %v at emulation version 1.25", g1v1rPinned)
+	}
+	if config.ResourceEnabled(g1v1rFuture) {
+		t.Errorf("expected disabled for %v at emulation version 1.25", g1v1rFuture)
+	}
+
+	// the override makes the pinned resource available without affecting the other.
+	config.SetResourceEmulationOverride(g1v1rPinned, utilversion.MajorMinor(1, 28))
+	if !config.ResourceEnabled(g1v1rPinned) {
+		t.Errorf("expected enabled for %v with an emulation override of 1.28", g1v1rPinned)
+	}
+	if config.ResourceEnabled(g1v1rFuture) {
+		t.Errorf("expected disabled for %v at emulation version 1.25", g1v1rFuture)
+	}
+
+	// a nil version removes the override.
+	config.SetResourceEmulationOverride(g1v1rPinned, nil)
+	if config.ResourceEnabled(g1v1rPinned) {
+		t.Errorf("expected disabled for %v after removing the override", g1v1rPinned)
+	}
+}
+
+func TestCountEnabled(t *testing.T) {
+	g1v1 := schema.GroupVersion{Group: "group1", Version: "version1"}
+	g1v2 := schema.GroupVersion{Group: "group1", Version: "version2"}
+	g2v1 := schema.GroupVersion{Group: "group2", Version: "version1"}
+
+	config := NewResourceConfig()
+	config.EnableVersions(g1v1, g2v1)
+	config.DisableVersions(g1v2)
+	config.DisableResources(g1v1.WithResource("disabled"))
+	config.EnableResources(g1v2.WithResource("enabled"))
+
+	knownGVs := []schema.GroupVersion{g1v1, g1v2, g2v1}
+	knownGVRs := []schema.GroupVersionResource{
+		g1v1.WithResource("unspecified"),
+		g1v1.WithResource("disabled"),
+		g1v2.WithResource("enabled"),
+		g1v2.WithResource("unspecified"),
+		g2v1.WithResource("unspecified"),
+	}
+
+	versions, resources := config.CountEnabled(knownGVs, knownGVRs)
+	if versions != 2 {
+		t.Errorf("expected 2 enabled versions, got %d", versions)
+	}
+	if resources != 3 {
+		t.Errorf("expected 3 enabled resources, got %d", resources)
+	}
+}
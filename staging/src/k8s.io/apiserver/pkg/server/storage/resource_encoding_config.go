@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	apimachineryversion "k8s.io/apimachinery/pkg/util/version"
 	version "k8s.io/component-base/version"
+	"k8s.io/klog/v2"
 )
 
 type ResourceEncodingConfig interface {
@@ -44,8 +45,15 @@ type DefaultResourceEncodingConfig struct {
 	resources        map[schema.GroupResource]*OverridingResourceEncoding
 	scheme           *runtime.Scheme
 	effectiveVersion version.EffectiveVersion
+	// storageEncodingFallback, if set, is consulted when a storage encoding cannot be determined
+	// for a resource instead of returning the error.
+	storageEncodingFallback StorageEncodingFallback
 }
 
+// StorageEncodingFallback chooses the storage version for a resource after the normal storage
+// encoding selection has failed with the given cause. Returning an error keeps the original failure.
+type StorageEncodingFallback func(resource schema.GroupResource, cause error) (schema.GroupVersion, error)
+
 type OverridingResourceEncoding struct {
 	ExternalResourceEncoding schema.GroupVersion
 	InternalResourceEncoding schema.GroupVersion
@@ -68,9 +76,28 @@ func (o *DefaultResourceEncodingConfig) SetEffectiveVersion(effectiveVersion ver
 	o.effectiveVersion = effectiveVersion
 }
 
+// SetStorageEncodingFallback installs a fallback invoked when a storage encoding cannot be
+// determined for a resource. The version it chooses is used with a logged warning instead of
+// failing. Without a fallback (the default), the error is returned unchanged.
+func (o *DefaultResourceEncodingConfig) SetStorageEncodingFallback(fallback StorageEncodingFallback) {
+	o.storageEncodingFallback = fallback
+}
+
+func (o *DefaultResourceEncodingConfig) fallbackStorageEncoding(resource schema.GroupResource, cause error) (schema.GroupVersion, error) {
+	if o.storageEncodingFallback == nil {
+		return schema.GroupVersion{}, cause
+	}
+	chosen, err := o.storageEncodingFallback(resource, cause)
+	if err != nil {
+		return schema.GroupVersion{}, cause
+	}
+	klog.Warningf("Falling back to storage version %v for %v: %v", chosen, resource, cause)
+	return chosen, nil
+}
+
 func (o *DefaultResourceEncodingConfig) StorageEncodingFor(resource schema.GroupResource) (schema.GroupVersion, error) {
 	if !o.scheme.IsGroupRegistered(resource.Group) {
-		return schema.GroupVersion{}, fmt.Errorf("group %q is not registered in scheme", resource.Group)
+		return o.fallbackStorageEncoding(resource, fmt.Errorf("group %q is not registered in scheme", resource.Group))
 	}
 
 	resourceOverride, resourceExists := o.resources[resource]
@@ -84,7 +111,7 @@ func (o *DefaultResourceEncodingConfig) StorageEncodingFor(resource schema.Group
 
 func (o *DefaultResourceEncodingConfig) BackwardCompatibileStorageEncodingFor(resource schema.GroupResource, example runtime.Object) (schema.GroupVersion, error) {
 	if !o.scheme.IsGroupRegistered(resource.Group) {
-		return schema.GroupVersion{}, fmt.Errorf("group %q is not registered in scheme", resource.Group)
+		return o.fallbackStorageEncoding(resource, fmt.Errorf("group %q is not registered in scheme", resource.Group))
 	}
 
 	// Always respect overrides
@@ -93,11 +120,52 @@ func (o *DefaultResourceEncodingConfig) BackwardCompatibileStorageEncodingFor(re
 		return resourceOverride.ExternalResourceEncoding, nil
 	}
 
-	return emulatedStorageVersion(
+	chosen, err := emulatedStorageVersion(
 		o.scheme.PrioritizedVersionsForGroup(resource.Group)[0],
 		example,
 		o.effectiveVersion,
 		o.scheme)
+	if err != nil {
+		return o.fallbackStorageEncoding(resource, err)
+	}
+	return chosen, nil
+}
+
+// StorageMigrationPath computes the storage version of the resource at the two emulation
+// versions and whether moving between them implies a storage migration. The example object
+// selects the group/kind, like in BackwardCompatibileStorageEncodingFor. This drives
+// storage-migration planning before an emulation version change is rolled out.
+func (o *DefaultResourceEncodingConfig) StorageMigrationPath(resource schema.GroupResource, example runtime.Object, from, to *apimachineryversion.Version) (fromVer, toVer schema.GroupVersion, migrationNeeded bool, err error) {
+	fromVer, err = o.storageEncodingForEmulationVersion(resource, example, from)
+	if err != nil {
+		return schema.GroupVersion{}, schema.GroupVersion{}, false, err
+	}
+	toVer, err = o.storageEncodingForEmulationVersion(resource, example, to)
+	if err != nil {
+		return schema.GroupVersion{}, schema.GroupVersion{}, false, err
+	}
+	return fromVer, toVer, fromVer != toVer, nil
+}
+
+// storageEncodingForEmulationVersion computes the storage version the config would choose if
+// the effective version emulated v, without changing the config.
+func (o *DefaultResourceEncodingConfig) storageEncodingForEmulationVersion(resource schema.GroupResource, example runtime.Object, v *apimachineryversion.Version) (schema.GroupVersion, error) {
+	if !o.scheme.IsGroupRegistered(resource.Group) {
+		return schema.GroupVersion{}, fmt.Errorf("group %q is not registered in scheme", resource.Group)
+	}
+
+	// Always respect overrides
+	if resourceOverride, resourceExists := o.resources[resource]; resourceExists {
+		return resourceOverride.ExternalResourceEncoding, nil
+	}
+
+	target := version.NewEffectiveVersion(o.effectiveVersion.BinaryVersion().String())
+	target.SetEmulationVersion(v)
+	return emulatedStorageVersion(
+		o.scheme.PrioritizedVersionsForGroup(resource.Group)[0],
+		example,
+		target,
+		o.scheme)
 }
 
 func (o *DefaultResourceEncodingConfig) InMemoryEncodingFor(resource schema.GroupResource) (schema.GroupVersion, error) {
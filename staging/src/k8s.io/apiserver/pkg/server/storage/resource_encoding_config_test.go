@@ -66,6 +66,42 @@ func TestEmulatedStorageVersion(t *testing.T) {
 	}
 }
 
+func TestStorageEncodingFallback(t *testing.T) {
+	unregistered := schema.GroupResource{Group: "unregistered.example.com", Resource: "cronjobs"}
+	scheme := AlphaBetaScheme(utilversion.MustParse("1.31"), utilversion.MustParse("1.32"))
+
+	// without a fallback the error is returned unchanged.
+	config := NewDefaultResourceEncodingConfig(scheme)
+	if _, err := config.StorageEncodingFor(unregistered); err == nil {
+		t.Fatal("expected error for unregistered group, got none")
+	}
+
+	// with a fallback the chosen version is used instead.
+	var gotCause error
+	config.SetStorageEncodingFallback(func(resource schema.GroupResource, cause error) (schema.GroupVersion, error) {
+		gotCause = cause
+		return v1beta1, nil
+	})
+	chosen, err := config.StorageEncodingFor(unregistered)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if chosen != v1beta1 {
+		t.Errorf("got %v; want %v", chosen, v1beta1)
+	}
+	if gotCause == nil {
+		t.Error("expected the fallback to receive the original cause")
+	}
+
+	// a fallback that declines keeps the original failure.
+	config.SetStorageEncodingFallback(func(resource schema.GroupResource, cause error) (schema.GroupVersion, error) {
+		return schema.GroupVersion{}, cause
+	})
+	if _, err := config.StorageEncodingFor(unregistered); err == nil {
+		t.Fatal("expected error when the fallback declines, got none")
+	}
+}
+
 var internalGV = schema.GroupVersion{Group: "workload.example.com", Version: runtime.APIVersionInternal}
 var v1alpha1 = schema.GroupVersion{Group: "workload.example.com", Version: "v1alpha1"}
 var v1beta1 = schema.GroupVersion{Group: "workload.example.com", Version: "v1beta1"}
@@ -133,3 +169,66 @@ func AlphaReplacedBetaScheme(alphaVersion, betaVersion *utilversion.Version) *ru
 	utilruntime.Must(runtimetesting.RegisterConversions(s))
 	return s
 }
+
+// migratingCronJob declares its lifecycle on the type, like generated API types do, so the
+// fresh objects built by scheme.New still carry the introduced versions.
+type migratingCronJob struct {
+	A string `json:"A,omitempty"`
+}
+
+func (*migratingCronJob) GetObjectKind() schema.ObjectKind { panic("not implemented") }
+func (*migratingCronJob) DeepCopyObject() runtime.Object   { panic("not implemented") }
+
+type migratingCronJobAlpha struct{ migratingCronJob }
+
+func (*migratingCronJobAlpha) APILifecycleIntroduced() (major, minor int) { return 1, 31 }
+
+type migratingCronJobBeta struct{ migratingCronJob }
+
+func (*migratingCronJobBeta) APILifecycleIntroduced() (major, minor int) { return 1, 32 }
+
+// MigratingScheme has an alpha version introduced in 1.31 and a preferred beta version
+// introduced in 1.32.
+func MigratingScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	s.AddKnownTypeWithName(internalGV.WithKind("MigratingCronJob"), &migratingCronJob{})
+	s.AddKnownTypeWithName(v1alpha1.WithKind("MigratingCronJob"), &migratingCronJobAlpha{})
+	s.AddKnownTypeWithName(v1beta1.WithKind("MigratingCronJob"), &migratingCronJobBeta{})
+	utilruntime.Must(s.SetVersionPriority(v1beta1, v1alpha1))
+	return s
+}
+
+func TestStorageMigrationPath(t *testing.T) {
+	scheme := MigratingScheme()
+	cronjobs := schema.GroupResource{Group: "workload.example.com", Resource: "cronjobs"}
+
+	config := NewDefaultResourceEncodingConfig(scheme)
+	config.SetEffectiveVersion(version.NewEffectiveVersion("1.33"))
+
+	// the beta version went GA after the min compatibility version of the lower endpoint,
+	// so raising the emulation version moves the storage version from alpha to beta.
+	fromVer, toVer, migrationNeeded, err := config.StorageMigrationPath(cronjobs, &migratingCronJobBeta{}, utilversion.MustParse("1.32"), utilversion.MustParse("1.33"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fromVer != v1alpha1 || toVer != v1beta1 {
+		t.Errorf("got %v -> %v; want %v -> %v", fromVer, toVer, v1alpha1, v1beta1)
+	}
+	if !migrationNeeded {
+		t.Error("expected a migration to be needed")
+	}
+
+	// both endpoints choose the same storage version, no migration.
+	fromVer, toVer, migrationNeeded, err = config.StorageMigrationPath(cronjobs, &migratingCronJobBeta{}, utilversion.MustParse("1.33"), utilversion.MustParse("1.33"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if migrationNeeded {
+		t.Errorf("expected no migration, got %v -> %v", fromVer, toVer)
+	}
+
+	// an unregistered group is an error.
+	if _, _, _, err := config.StorageMigrationPath(schema.GroupResource{Group: "unregistered.example.com", Resource: "cronjobs"}, &migratingCronJobBeta{}, utilversion.MustParse("1.32"), utilversion.MustParse("1.33")); err == nil {
+		t.Error("expected error for unregistered group, got none")
+	}
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testing provides helpers for exercising resource lifecycle behavior in tests.
+package testing
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+	serverstorage "k8s.io/apiserver/pkg/server/storage"
+)
+
+// SetResourceIntroducedRemoved records the lifecycle window of the resource on the config,
+// replacing the repetitive construction of lifecycle structs across tests.
+// A nil introduced or removed version leaves that end of the window open.
+func SetResourceIntroducedRemoved(config *serverstorage.ResourceConfig, gvr schema.GroupVersionResource, introduced, removed *version.Version) {
+	config.SetResourceLifecycle(gvr, serverstorage.ResourceLifecycle{
+		IntroducedVersion: introduced,
+		RemovedVersion:    removed,
+	})
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+	serverstorage "k8s.io/apiserver/pkg/server/storage"
+)
+
+func TestSetResourceIntroducedRemoved(t *testing.T) {
+	gv := schema.GroupVersion{Group: "one", Version: "v1"}
+	gvr := gv.WithResource("foos")
+
+	config := serverstorage.NewResourceConfig()
+	config.EnableVersions(gv)
+	SetResourceIntroducedRemoved(config, gvr, version.MajorMinor(1, 29), version.MajorMinor(1, 32))
+
+	tests := []struct {
+		emulationVersion *version.Version
+		expectEnabled    bool
+	}{
+		{version.MajorMinor(1, 28), false},
+		{version.MajorMinor(1, 29), true},
+		{version.MajorMinor(1, 31), true},
+		{version.MajorMinor(1, 32), false},
+	}
+	for _, test := range tests {
+		view := config.AtEmulationVersion(test.emulationVersion)
+		if actual := view.ResourceEnabled(gvr); actual != test.expectEnabled {
+			t.Errorf("at %s: expected enabled=%v, got %v", test.emulationVersion.String(), test.expectEnabled, actual)
+		}
+	}
+}
@@ -17,13 +17,16 @@ limitations under the License.
 package featuregate
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"sync"
 
 	"github.com/spf13/pflag"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/version"
 	cliflag "k8s.io/component-base/cli/flag"
 	baseversion "k8s.io/component-base/version"
@@ -55,16 +58,69 @@ import (
 // flags := cmd.Flags()
 // // add flags
 // utilversion.DefaultComponentGlobalsRegistry.AddFlags(flags)
+//
+// Tests must not share this registry across parallel tests: Set mutates the effective
+// version pointers that other tests read through EffectiveVersionFor, so concurrent use
+// races despite the internal mutex. Use RegistryForTest for an isolated registry instead.
 var DefaultComponentGlobalsRegistry ComponentGlobalsRegistry = NewComponentGlobalsRegistry()
 
+// RegistryForTest returns a fresh isolated registry, so parallel tests do not share the
+// global state of DefaultComponentGlobalsRegistry.
+func RegistryForTest() ComponentGlobalsRegistry {
+	return NewComponentGlobalsRegistry()
+}
+
 const (
 	DefaultKubeComponent = "kube"
 
 	klogLevel = 2
 )
 
+// Sentinel errors wrapped by the registry so that callers can distinguish the failure
+// modes with errors.Is and produce better CLI messages.
+var (
+	// ErrPatchVersionNotAllowed is returned when a version config carries a patch version.
+	ErrPatchVersionNotAllowed = errors.New("patch version not allowed")
+	// ErrComponentNotRegistered is returned when a version or feature gate config names a
+	// component that is not registered.
+	ErrComponentNotRegistered = errors.New("component not registered")
+	// ErrVersionMappingLoop is returned when expanding the version mappings sets the version
+	// of a component more than once.
+	ErrVersionMappingLoop = errors.New("version mapping loop")
+)
+
 type VersionMapping func(from *version.Version) *version.Version
 
+// ChainVersionMapping composes the given mappings into one that applies them in sequence,
+// so callers can build "subtract one minor, then clamp to the floor" style mappings
+// declaratively. A nil result from any mapping short-circuits the chain, and nil mappings
+// are skipped.
+func ChainVersionMapping(fs ...VersionMapping) VersionMapping {
+	return func(from *version.Version) *version.Version {
+		ver := from
+		for _, f := range fs {
+			if f == nil {
+				continue
+			}
+			if ver == nil {
+				return nil
+			}
+			ver = f(ver)
+		}
+		return ver
+	}
+}
+
+// MultiVersionMapping computes a version from the versions of several source components,
+// keyed by component name.
+type MultiVersionMapping func(froms map[string]*version.Version) *version.Version
+
+// multiVersionMapping records a mapping from several source components to one target component.
+type multiVersionMapping struct {
+	fromComponents []string
+	f              MultiVersionMapping
+}
+
 // ComponentGlobals stores the global variables for a component for easy access.
 type ComponentGlobals struct {
 	effectiveVersion baseversion.MutableEffectiveVersion
@@ -94,53 +150,200 @@ type ComponentGlobalsRegistry interface {
 	// Register registers the EffectiveVersion and FeatureGate for a component.
 	// returns error if the component is already registered.
 	Register(component string, effectiveVersion baseversion.MutableEffectiveVersion, featureGate MutableVersionedFeatureGate) error
+	// RegisterFeatureGateFollowing registers a component that only contributes a feature gate
+	// and shares the emulation version of another component. The component is registered with
+	// an emulation version mapped 1:1 from followComponent, so it tracks the followed
+	// component and cannot be set independently.
+	RegisterFeatureGateFollowing(component, followComponent string, featureGate MutableVersionedFeatureGate) error
 	// ComponentGlobalsOrRegister would return the registered global variables for the component if it already exists in the registry.
 	// Otherwise, the provided variables would be registered under the component, and the same variables would be returned.
 	ComponentGlobalsOrRegister(component string, effectiveVersion baseversion.MutableEffectiveVersion, featureGate MutableVersionedFeatureGate) (baseversion.MutableEffectiveVersion, MutableVersionedFeatureGate)
 	// AddFlags adds flags of "--emulated-version" and "--feature-gates"
 	AddFlags(fs *pflag.FlagSet)
+	// SetDefaultFeatureGateComponent sets the component a feature-gate token without a
+	// component prefix is attributed to. Defaults to DefaultKubeComponent.
+	SetDefaultFeatureGateComponent(component string)
+	// SetEmulationVersionConfig records emulation version overrides from an additional named
+	// source (e.g. a config file), applied in Set() together with the flag values.
+	// Set() returns an error if two sources assign different versions to the same component.
+	SetEmulationVersionConfig(source string, versionConfig []string)
 	// Set sets the flags for all global variables for all components registered.
 	Set() error
+	// SetVersions applies emulation versions and feature gates directly, performing the same
+	// expansion and application as the flag-driven Set(), for embedders that do not use pflag.
+	SetVersions(emulationVersions map[string]string, featureGates map[string][]string) error
+	// ValidateProposedEmulationVersions checks a proposed emulation-version map against each
+	// component's allowed emulation version range and the registered mapping constraints,
+	// without applying anything. Tooling can call it before Set() to surface all problems
+	// with a proposed configuration at once.
+	ValidateProposedEmulationVersions(emulationVersions map[string]string) []error
+	// DependentEmulationComponents returns the sorted components whose emulation version is
+	// derived through a mapping and therefore cannot be set with the emulated-version flag.
+	DependentEmulationComponents() []string
+	// DependentMinCompatibilityComponents returns the sorted components whose min compatibility
+	// version is derived through a mapping.
+	DependentMinCompatibilityComponents() []string
+	// ResolveEmulationVersion computes what the emulation versions of the component and its
+	// mapping dependents would be if the component were set to the given value, without
+	// applying anything to the registry.
+	ResolveEmulationVersion(component string, value string) (map[string]*version.Version, error)
+	// KnownFeatures returns the known features of the feature gate registered under the
+	// component, without the component prefix used in the combined flag help.
+	// Returns an error if the component or its feature gate is not registered.
+	KnownFeatures(component string) ([]string, error)
+	// FeatureDefaultDiff returns the features of the component's feature gate whose default
+	// or availability changes between the two versions, mapped to their defaults at [from, to].
+	// A feature unavailable at a version reports false for that side. Returns nil if the
+	// component or its feature gate is not registered.
+	FeatureDefaultDiff(component string, from, to *version.Version) map[Feature][2]bool
+	// EmulationChangedFeatureDefaults returns, per component, the features whose default at
+	// the emulation version applied by the last Set() differs from their default at the
+	// binary version, explaining why a feature is off at a lower emulation version.
+	EmulationChangedFeatureDefaults() map[string][]Feature
 	// SetFallback calls Set() if it has never been called.
 	SetFallback() error
 	// Validate calls the Validate() function for all the global variables for all components registered.
 	Validate() []error
+	// AssertAllFeatureGatesSynced verifies that the emulation version of every registered
+	// feature gate equals the emulation version of its component. A gate registered after
+	// Set() ran silently keeps its default emulation version, so this is meant as a guard
+	// to be called post-Set.
+	AssertAllFeatureGatesSynced() []error
 	// Reset removes all stored ComponentGlobals, configurations, and version mappings.
 	Reset()
+	// RegisterAlias registers an alias usable in place of a component name in the emulated-version flag.
+	// Setting the emulation version through the alias sets it for every member component.
+	// The alias must not conflict with a registered component name, and all members must be registered.
+	RegisterAlias(alias string, members ...string) error
 	// SetEmulationVersionMapping sets the mapping from the emulation version of one component
 	// to the emulation version of another component.
 	// Once set, the emulation version of the toComponent will be determined by the emulation version of the fromComponent,
 	// and cannot be set from cmd flags anymore.
 	// For a given component, its emulation version can only depend on one other component, no multiple dependency is allowed.
 	SetEmulationVersionMapping(fromComponent, toComponent string, f VersionMapping) error
+	// SetEmulationVersionMappingN sets the mapping from the emulation versions of several components
+	// to the emulation version of another component.
+	// The emulation version of the toComponent is resolved only after all the fromComponents have their
+	// final versions, and cannot be set from cmd flags or another mapping anymore.
+	SetEmulationVersionMappingN(fromComponents []string, toComponent string, f MultiVersionMapping) error
+	// SetEmulationVersionMappings sets several emulation version mappings atomically: the whole
+	// batch is validated first, including cycle detection across the new and existing mappings,
+	// and on any failure no mapping is added.
+	SetEmulationVersionMappings(mappings []Mapping) error
+	// EmulationVersionFlagValue returns a comp=ver,comp=ver string that would reproduce the
+	// current emulation versions when passed to the emulated-version flag. Components whose
+	// emulation version is derived through a mapping cannot be set from the flag and are
+	// only included when includeDerived is true.
+	EmulationVersionFlagValue(includeDerived bool) string
+	// EmulationVersionMappings returns, per source component, the sorted list of dependent
+	// components whose emulation version it drives, as a read-only view of the mapping graph
+	// for diagnostics and visualization.
+	EmulationVersionMappings() map[string][]string
+	// ResolvedFeatureSpec returns the spec governing the feature at the emulation version of
+	// the component's feature gate, i.e. the latest versioned spec whose version is at most
+	// the emulation version, and whether the feature is known to the component.
+	ResolvedFeatureSpec(component string, feature Feature) (FeatureSpec, bool)
+}
+
+// Mapping describes one emulation version mapping edge for SetEmulationVersionMappings.
+type Mapping struct {
+	FromComponent string
+	ToComponent   string
+	F             VersionMapping
 }
 
 type componentGlobalsRegistry struct {
 	componentGlobals map[string]*ComponentGlobals
-	mutex            sync.RWMutex
+	// componentAliases maps an alias usable in the emulated-version flag to its member components.
+	componentAliases map[string][]string
+	// multiEmulationVersionMappings maps a target component to the multi-source mapping that
+	// determines its emulation version.
+	multiEmulationVersionMappings map[string]multiVersionMapping
+	mutex                         sync.RWMutex
 	// list of component name to emulation version set from the flag.
 	emulationVersionConfig []string
+	// emulationVersionSourceConfigs stores emulation version overrides from additional named
+	// sources (e.g. config files), merged with the flag values in Set().
+	emulationVersionSourceConfigs map[string][]string
 	// map of component name to the list of feature gates set from the flag.
 	featureGatesConfig map[string][]string
+	// defaultFeatureGateComponent is the component feature gates without a component prefix
+	// are attributed to. Empty means DefaultKubeComponent.
+	defaultFeatureGateComponent string
+	// emulationChangedFeatureDefaults records, per component, the features whose default at
+	// the emulation version applied by the last Set() differs from their default at the binary version.
+	emulationChangedFeatureDefaults map[string][]Feature
 	// set stores if the Set() function for the registry is already called.
 	set bool
+	// registrationErrs stores the errors from the RegistryOptions applied at construction,
+	// and is surfaced through Validate().
+	registrationErrs []error
 }
 
-func NewComponentGlobalsRegistry() *componentGlobalsRegistry {
-	return &componentGlobalsRegistry{
-		componentGlobals:       make(map[string]*ComponentGlobals),
-		emulationVersionConfig: nil,
-		featureGatesConfig:     nil,
+// RegistryOption is applied to the registry in NewComponentGlobalsRegistry.
+type RegistryOption func(*componentGlobalsRegistry) error
+
+// WithComponent registers the EffectiveVersion and FeatureGate for a component at registry construction.
+func WithComponent(component string, effectiveVersion baseversion.MutableEffectiveVersion, featureGate MutableVersionedFeatureGate) RegistryOption {
+	return func(r *componentGlobalsRegistry) error {
+		if effectiveVersion == nil {
+			return fmt.Errorf("cannot register nil effectiveVersion")
+		}
+		return r.unsafeRegister(component, effectiveVersion, featureGate)
 	}
 }
 
+func NewComponentGlobalsRegistry(opts ...RegistryOption) *componentGlobalsRegistry {
+	r := &componentGlobalsRegistry{
+		componentGlobals:              make(map[string]*ComponentGlobals),
+		componentAliases:              make(map[string][]string),
+		multiEmulationVersionMappings: make(map[string]multiVersionMapping),
+		emulationVersionConfig:        nil,
+		featureGatesConfig:            nil,
+	}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			r.registrationErrs = append(r.registrationErrs, err)
+		}
+	}
+	return r
+}
+
+// RegisterDefaultKubeComponent registers the DefaultKubeComponent in the registry with the
+// EffectiveVersion from the current build information and the provided feature gate,
+// and returns the registered globals.
+// If the kube component is already registered, the existing globals are returned instead.
+func RegisterDefaultKubeComponent(registry ComponentGlobalsRegistry, featureGate MutableVersionedFeatureGate) (baseversion.MutableEffectiveVersion, MutableVersionedFeatureGate) {
+	return registry.ComponentGlobalsOrRegister(DefaultKubeComponent, baseversion.DefaultBuildEffectiveVersion(), featureGate)
+}
+
 func (r *componentGlobalsRegistry) Reset() {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	r.componentGlobals = make(map[string]*ComponentGlobals)
+	r.componentAliases = make(map[string][]string)
+	r.multiEmulationVersionMappings = make(map[string]multiVersionMapping)
 	r.emulationVersionConfig = nil
+	r.emulationVersionSourceConfigs = nil
 	r.featureGatesConfig = nil
+	r.defaultFeatureGateComponent = ""
+	r.emulationChangedFeatureDefaults = nil
 	r.set = false
+	r.registrationErrs = nil
+}
+
+func (r *componentGlobalsRegistry) SetDefaultFeatureGateComponent(component string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.defaultFeatureGateComponent = component
+}
+
+// unsafeDefaultFeatureGateComponent returns the component unprefixed feature gates default to.
+func (r *componentGlobalsRegistry) unsafeDefaultFeatureGateComponent() string {
+	if r.defaultFeatureGateComponent != "" {
+		return r.defaultFeatureGateComponent
+	}
+	return DefaultKubeComponent
 }
 
 func (r *componentGlobalsRegistry) EffectiveVersionFor(component string) baseversion.EffectiveVersion {
@@ -191,6 +394,25 @@ func (r *componentGlobalsRegistry) Register(component string, effectiveVersion b
 	return r.unsafeRegister(component, effectiveVersion, featureGate)
 }
 
+// RegisterFeatureGateFollowing registers a component that only contributes a feature gate and
+// shares the emulation version of another, already registered component via a 1:1 mapping.
+func (r *componentGlobalsRegistry) RegisterFeatureGateFollowing(component, followComponent string, featureGate MutableVersionedFeatureGate) error {
+	if featureGate == nil {
+		return fmt.Errorf("cannot register nil featureGate")
+	}
+	followedVersion := r.EffectiveVersionFor(followComponent)
+	if followedVersion == nil {
+		return fmt.Errorf("%w: %s", ErrComponentNotRegistered, followComponent)
+	}
+	effectiveVersion := baseversion.NewEffectiveVersion(followedVersion.BinaryVersion().String())
+	effectiveVersion.SetEmulationVersion(followedVersion.EmulationVersion())
+	if err := r.Register(component, effectiveVersion, featureGate); err != nil {
+		return err
+	}
+	return r.SetEmulationVersionMapping(followComponent, component,
+		func(from *version.Version) *version.Version { return from })
+}
+
 func (r *componentGlobalsRegistry) ComponentGlobalsOrRegister(component string, effectiveVersion baseversion.MutableEffectiveVersion, featureGate MutableVersionedFeatureGate) (baseversion.MutableEffectiveVersion, MutableVersionedFeatureGate) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
@@ -202,6 +424,45 @@ func (r *componentGlobalsRegistry) ComponentGlobalsOrRegister(component string,
 	return effectiveVersion, featureGate
 }
 
+func (r *componentGlobalsRegistry) RegisterAlias(alias string, members ...string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.componentGlobals[alias]; ok {
+		return fmt.Errorf("alias %s conflicts with a registered component name", alias)
+	}
+	if _, ok := r.componentAliases[alias]; ok {
+		return fmt.Errorf("alias %s already registered", alias)
+	}
+	if len(members) == 0 {
+		return fmt.Errorf("alias %s must have at least one member component", alias)
+	}
+	for _, member := range members {
+		if _, ok := r.componentGlobals[member]; !ok {
+			return fmt.Errorf("%w: %s", ErrComponentNotRegistered, member)
+		}
+	}
+	r.componentAliases[alias] = append([]string{}, members...)
+	return nil
+}
+
+// unsafeExpandAliases replaces alias keys in the version config with their member components.
+func (r *componentGlobalsRegistry) unsafeExpandAliases(versionConfigMap map[string]*version.Version) error {
+	for alias, members := range r.componentAliases {
+		ver, ok := versionConfigMap[alias]
+		if !ok {
+			continue
+		}
+		delete(versionConfigMap, alias)
+		for _, member := range members {
+			if existingVer, ok := versionConfigMap[member]; ok {
+				return fmt.Errorf("duplicate version flag, %s=%s and %s=%s", alias, ver.String(), member, existingVer.String())
+			}
+			versionConfigMap[member] = ver
+		}
+	}
+	return nil
+}
+
 func (r *componentGlobalsRegistry) unsafeKnownFeatures() []string {
 	var known []string
 	for component, globals := range r.componentGlobals {
@@ -216,6 +477,77 @@ func (r *componentGlobalsRegistry) unsafeKnownFeatures() []string {
 	return known
 }
 
+// KnownFeatures returns the known features of the feature gate registered under the component.
+func (r *componentGlobalsRegistry) KnownFeatures(component string) ([]string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	globals, ok := r.componentGlobals[component]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrComponentNotRegistered, component)
+	}
+	if globals.featureGate == nil {
+		return nil, fmt.Errorf("component featureGate not registered: %s", component)
+	}
+	known := globals.featureGate.KnownFeatures()
+	sort.Strings(known)
+	return known, nil
+}
+
+// EmulationVersionMappings returns the emulation version mapping graph: for each source
+// component, the dependent components it drives, sorted for deterministic output.
+func (r *componentGlobalsRegistry) EmulationVersionMappings() map[string][]string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	mappings := map[string][]string{}
+	for fromComponent, globals := range r.componentGlobals {
+		if len(globals.emulationVersionMapping) == 0 {
+			continue
+		}
+		dependents := make([]string, 0, len(globals.emulationVersionMapping))
+		for toComponent := range globals.emulationVersionMapping {
+			dependents = append(dependents, toComponent)
+		}
+		sort.Strings(dependents)
+		mappings[fromComponent] = dependents
+	}
+	return mappings
+}
+
+// ResolvedFeatureSpec returns the spec governing the feature at the emulation version of the
+// component's feature gate, and whether the feature is known to the component.
+func (r *componentGlobalsRegistry) ResolvedFeatureSpec(component string, feature Feature) (FeatureSpec, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	globals, ok := r.componentGlobals[component]
+	if !ok || globals.featureGate == nil {
+		return FeatureSpec{}, false
+	}
+	specs, ok := globals.featureGate.GetAllVersioned()[feature]
+	if !ok {
+		return FeatureSpec{}, false
+	}
+	return *featureSpecAtEmulationVersion(specs, globals.featureGate.EmulationVersion()), true
+}
+
+// EmulationVersionFlagValue returns the emulated-version flag value that would reproduce the
+// current emulation versions, e.g. for support-bundle reproduction of a running config.
+func (r *componentGlobalsRegistry) EmulationVersionFlagValue(includeDerived bool) string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	var vs []string
+	for component, globals := range r.componentGlobals {
+		if globals.effectiveVersion == nil {
+			continue
+		}
+		if globals.dependentEmulationVersion && !includeDerived {
+			continue
+		}
+		vs = append(vs, fmt.Sprintf("%s=%s", component, globals.effectiveVersion.EmulationVersion().String()))
+	}
+	sort.Strings(vs)
+	return strings.Join(vs, ",")
+}
+
 func (r *componentGlobalsRegistry) unsafeVersionFlagOptions(isEmulation bool) []string {
 	var vs []string
 	for component, globals := range r.componentGlobals {
@@ -252,6 +584,8 @@ func (r *componentGlobalsRegistry) AddFlags(fs *pflag.FlagSet) {
 			globals.featureGate.Close()
 		}
 	}
+	// Reset() clears the stored configs, so only warn when AddFlags is called
+	// again without an intervening Reset, which would silently drop the earlier flag values.
 	if r.emulationVersionConfig != nil || r.featureGatesConfig != nil {
 		klog.Warning("calling componentGlobalsRegistry.AddFlags more than once, the registry will be set by the latest flags")
 	}
@@ -282,25 +616,58 @@ func (r *componentGlobalsRegistry) getFullEmulationVersionConfig(
 	setQueue := []componentVersion{}
 	for comp, ver := range versionConfigMap {
 		if _, ok := r.componentGlobals[comp]; !ok {
-			return result, fmt.Errorf("component not registered: %s", comp)
+			return result, fmt.Errorf("%w: %s", ErrComponentNotRegistered, comp)
 		}
 		klog.V(klogLevel).Infof("setting version %s=%s", comp, ver.String())
 		setQueue = append(setQueue, componentVersion{comp, ver})
 	}
-	for len(setQueue) > 0 {
-		cv := setQueue[0]
-		if _, visited := result[cv.component]; visited {
-			return result, fmt.Errorf("setting version of %s more than once, probably version mapping loop", cv.component)
+	for {
+		for len(setQueue) > 0 {
+			cv := setQueue[0]
+			if _, visited := result[cv.component]; visited {
+				return result, fmt.Errorf("setting version of %s more than once, probably %w", cv.component, ErrVersionMappingLoop)
+			}
+			setQueue = setQueue[1:]
+			result[cv.component] = cv.ver
+			for toComp, f := range r.componentGlobals[cv.component].emulationVersionMapping {
+				toVer := f(cv.ver)
+				if toVer == nil {
+					return result, fmt.Errorf("got nil version from mapping of %s=%s to component:%s", cv.component, cv.ver.String(), toComp)
+				}
+				klog.V(klogLevel).Infof("setting version %s=%s from version mapping of %s=%s", toComp, toVer.String(), cv.component, cv.ver.String())
+				setQueue = append(setQueue, componentVersion{toComp, toVer})
+			}
 		}
-		setQueue = setQueue[1:]
-		result[cv.component] = cv.ver
-		for toComp, f := range r.componentGlobals[cv.component].emulationVersionMapping {
-			toVer := f(cv.ver)
+		// multi-source mappings are resolved only after all their sources have their final versions.
+		// Sources that are not part of this config keep their current emulation version.
+		progressed := false
+		for toComp, m := range r.multiEmulationVersionMappings {
+			if _, visited := result[toComp]; visited {
+				continue
+			}
+			froms := map[string]*version.Version{}
+			anySourceInConfig := false
+			for _, fromComp := range m.fromComponents {
+				if ver, ok := result[fromComp]; ok {
+					froms[fromComp] = ver
+					anySourceInConfig = true
+				} else {
+					froms[fromComp] = r.componentGlobals[fromComp].effectiveVersion.EmulationVersion()
+				}
+			}
+			if !anySourceInConfig {
+				continue
+			}
+			toVer := m.f(froms)
 			if toVer == nil {
-				return result, fmt.Errorf("got nil version from mapping of %s=%s to component:%s", cv.component, cv.ver.String(), toComp)
+				return result, fmt.Errorf("got nil version from multi-source mapping to component:%s", toComp)
 			}
-			klog.V(klogLevel).Infof("setting version %s=%s from version mapping of %s=%s", toComp, toVer.String(), cv.component, cv.ver.String())
+			klog.V(klogLevel).Infof("setting version %s=%s from multi-source version mapping of %v", toComp, toVer.String(), m.fromComponents)
 			setQueue = append(setQueue, componentVersion{toComp, toVer})
+			progressed = true
+		}
+		if !progressed {
+			break
 		}
 	}
 	return result, nil
@@ -325,7 +692,7 @@ func toVersionMap(versionConfig []string) (map[string]*version.Version, error) {
 			return m, err
 		}
 		if ver.Patch() != 0 {
-			return m, fmt.Errorf("patch version not allowed, got: %s=%s", k, ver.String())
+			return m, fmt.Errorf("%w, got: %s=%s", ErrPatchVersionNotAllowed, k, ver.String())
 		}
 		if existingVer, ok := m[k]; ok {
 			return m, fmt.Errorf("duplicate version flag, %s=%s and %s=%s", k, existingVer.String(), k, ver.String())
@@ -335,6 +702,48 @@ func toVersionMap(versionConfig []string) (map[string]*version.Version, error) {
 	return m, nil
 }
 
+func (r *componentGlobalsRegistry) SetEmulationVersionConfig(source string, versionConfig []string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.emulationVersionSourceConfigs == nil {
+		r.emulationVersionSourceConfigs = make(map[string][]string)
+	}
+	r.emulationVersionSourceConfigs[source] = versionConfig
+}
+
+// unsafeMergeSourceConfigs merges the emulation version configs of the additional named sources
+// into the flag config. Two sources assigning different versions to the same component is an
+// error naming both values; assigning the same version is allowed.
+func (r *componentGlobalsRegistry) unsafeMergeSourceConfigs(versionConfigMap map[string]*version.Version) error {
+	sourceOfComponent := map[string]string{}
+	for comp := range versionConfigMap {
+		sourceOfComponent[comp] = "flags"
+	}
+	sources := make([]string, 0, len(r.emulationVersionSourceConfigs))
+	for source := range r.emulationVersionSourceConfigs {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		sourceConfigMap, err := toVersionMap(r.emulationVersionSourceConfigs[source])
+		if err != nil {
+			return fmt.Errorf("invalid emulation version config from %s: %w", source, err)
+		}
+		for comp, ver := range sourceConfigMap {
+			if existingVer, ok := versionConfigMap[comp]; ok {
+				if !existingVer.EqualTo(ver) {
+					return fmt.Errorf("conflicting emulation versions for component %s: %s from %s and %s from %s",
+						comp, existingVer.String(), sourceOfComponent[comp], ver.String(), source)
+				}
+				continue
+			}
+			versionConfigMap[comp] = ver
+			sourceOfComponent[comp] = source
+		}
+	}
+	return nil
+}
+
 func (r *componentGlobalsRegistry) SetFallback() error {
 	r.mutex.Lock()
 	set := r.set
@@ -350,14 +759,147 @@ func (r *componentGlobalsRegistry) SetFallback() error {
 func (r *componentGlobalsRegistry) Set() error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	return r.unsafeSet(r.emulationVersionConfig, r.featureGatesConfig)
+}
+
+func (r *componentGlobalsRegistry) SetVersions(emulationVersions map[string]string, featureGates map[string][]string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	emulationVersionConfig := make([]string, 0, len(emulationVersions))
+	for comp, ver := range emulationVersions {
+		emulationVersionConfig = append(emulationVersionConfig, fmt.Sprintf("%s=%s", comp, ver))
+	}
+	sort.Strings(emulationVersionConfig)
+	featureGatesConfig := make(map[string][]string, len(featureGates))
+	for comp, fg := range featureGates {
+		featureGatesConfig[comp] = append([]string{}, fg...)
+	}
+	return r.unsafeSet(emulationVersionConfig, featureGatesConfig)
+}
+
+func (r *componentGlobalsRegistry) ValidateProposedEmulationVersions(emulationVersions map[string]string) []error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	var errs []error
+	versionConfigMap := map[string]*version.Version{}
+	comps := make([]string, 0, len(emulationVersions))
+	for comp := range emulationVersions {
+		comps = append(comps, comp)
+	}
+	sort.Strings(comps)
+	for _, comp := range comps {
+		ver, err := version.Parse(emulationVersions[comp])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid emulation version for component %s: %w", comp, err))
+			continue
+		}
+		if ver.Patch() != 0 {
+			errs = append(errs, fmt.Errorf("%w, got: %s=%s", ErrPatchVersionNotAllowed, comp, ver.String()))
+			continue
+		}
+		versionConfigMap[comp] = ver
+	}
+	if err := r.unsafeExpandAliases(versionConfigMap); err != nil {
+		return append(errs, err)
+	}
+	for comp := range versionConfigMap {
+		if _, ok := r.componentGlobals[comp]; !ok {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrComponentNotRegistered, comp))
+			delete(versionConfigMap, comp)
+			continue
+		}
+		if r.componentGlobals[comp].dependentEmulationVersion {
+			errs = append(errs, fmt.Errorf("EmulationVersion of %s is set by mapping, cannot set it directly", comp))
+			delete(versionConfigMap, comp)
+		}
+	}
+	// expand the mappings so that derived components are checked against their ranges as well.
+	expanded, err := r.getFullEmulationVersionConfig(versionConfigMap)
+	if err != nil {
+		return append(errs, err)
+	}
+	expandedComps := make([]string, 0, len(expanded))
+	for comp := range expanded {
+		expandedComps = append(expandedComps, comp)
+	}
+	sort.Strings(expandedComps)
+	for _, comp := range expandedComps {
+		ver := expanded[comp]
+		minVer, maxVer := r.componentGlobals[comp].effectiveVersion.AllowedEmulationVersionRange()
+		if ver.GreaterThan(maxVer) || ver.LessThan(minVer) {
+			errs = append(errs, fmt.Errorf("emulation version %s of component %s is not between [%s, %s]",
+				ver.String(), comp, minVer.String(), maxVer.String()))
+		}
+	}
+	return errs
+}
+
+func (r *componentGlobalsRegistry) DependentEmulationComponents() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	var components []string
+	for comp, globals := range r.componentGlobals {
+		if globals.dependentEmulationVersion {
+			components = append(components, comp)
+		}
+	}
+	sort.Strings(components)
+	return components
+}
+
+func (r *componentGlobalsRegistry) DependentMinCompatibilityComponents() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	var components []string
+	for comp, globals := range r.componentGlobals {
+		if globals.dependentMinCompatibilityVersion {
+			components = append(components, comp)
+		}
+	}
+	sort.Strings(components)
+	return components
+}
+
+func (r *componentGlobalsRegistry) ResolveEmulationVersion(component string, value string) (map[string]*version.Version, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	ver, err := version.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	if ver.Patch() != 0 {
+		return nil, fmt.Errorf("%w, got: %s=%s", ErrPatchVersionNotAllowed, component, ver.String())
+	}
+	versionConfigMap := map[string]*version.Version{component: ver}
+	if err := r.unsafeExpandAliases(versionConfigMap); err != nil {
+		return nil, err
+	}
+	for comp := range versionConfigMap {
+		if _, ok := r.componentGlobals[comp]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrComponentNotRegistered, comp)
+		}
+		if r.componentGlobals[comp].dependentEmulationVersion {
+			return nil, fmt.Errorf("EmulationVersion of %s is set by mapping, cannot set it directly", comp)
+		}
+	}
+	return r.getFullEmulationVersionConfig(versionConfigMap)
+}
+
+func (r *componentGlobalsRegistry) unsafeSet(emulationVersionConfig []string, featureGatesConfig map[string][]string) error {
 	r.set = true
-	emulationVersionConfigMap, err := toVersionMap(r.emulationVersionConfig)
+	emulationVersionConfigMap, err := toVersionMap(emulationVersionConfig)
 	if err != nil {
 		return err
 	}
+	if err := r.unsafeMergeSourceConfigs(emulationVersionConfigMap); err != nil {
+		return err
+	}
+	if err := r.unsafeExpandAliases(emulationVersionConfigMap); err != nil {
+		return err
+	}
 	for comp := range emulationVersionConfigMap {
 		if _, ok := r.componentGlobals[comp]; !ok {
-			return fmt.Errorf("component not registered: %s", comp)
+			return fmt.Errorf("%w: %s", ErrComponentNotRegistered, comp)
 		}
 		// only components without any dependencies can be set from the flag.
 		if r.componentGlobals[comp].dependentEmulationVersion {
@@ -372,6 +914,7 @@ func (r *componentGlobalsRegistry) Set() error {
 		}
 	}
 	// Set feature gate emulation version before setting feature gate flag values.
+	r.emulationChangedFeatureDefaults = make(map[string][]Feature)
 	for comp, globals := range r.componentGlobals {
 		if globals.featureGate == nil {
 			continue
@@ -380,16 +923,21 @@ func (r *componentGlobalsRegistry) Set() error {
 		if err := globals.featureGate.SetEmulationVersion(globals.effectiveVersion.EmulationVersion()); err != nil {
 			return err
 		}
+		if changed := featureDefaultsChangedBetween(globals.featureGate, globals.effectiveVersion.BinaryMajorMinor(), globals.effectiveVersion.EmulationVersion()); len(changed) > 0 {
+			klog.V(klogLevel).Infof("defaults of %s features %v changed by emulation version %s", comp, changed, globals.effectiveVersion.EmulationVersion().String())
+			r.emulationChangedFeatureDefaults[comp] = changed
+		}
 	}
-	for comp, fg := range r.featureGatesConfig {
+	defaultComponent := r.unsafeDefaultFeatureGateComponent()
+	for comp, fg := range featureGatesConfig {
 		if comp == "" {
-			if _, ok := r.featureGatesConfig[DefaultKubeComponent]; ok {
-				return fmt.Errorf("set kube feature gates with default empty prefix or kube: prefix consistently, do not mix use")
+			if _, ok := featureGatesConfig[defaultComponent]; ok {
+				return fmt.Errorf("set %s feature gates with default empty prefix or %s: prefix consistently, do not mix use", defaultComponent, defaultComponent)
 			}
-			comp = DefaultKubeComponent
+			comp = defaultComponent
 		}
 		if _, ok := r.componentGlobals[comp]; !ok {
-			return fmt.Errorf("component not registered: %s", comp)
+			return fmt.Errorf("%w: %s", ErrComponentNotRegistered, comp)
 		}
 		featureGate := r.componentGlobals[comp].featureGate
 		if featureGate == nil {
@@ -401,17 +949,139 @@ func (r *componentGlobalsRegistry) Set() error {
 			return err
 		}
 	}
-	return nil
+	// re-validate every feature gate now that both the emulation versions and the flag values
+	// are applied, so values incompatible with the new emulation version surface here.
+	var validationErrs []error
+	comps := make([]string, 0, len(r.componentGlobals))
+	for comp := range r.componentGlobals {
+		comps = append(comps, comp)
+	}
+	sort.Strings(comps)
+	for _, comp := range comps {
+		if featureGate := r.componentGlobals[comp].featureGate; featureGate != nil {
+			validationErrs = append(validationErrs, featureGate.Validate()...)
+		}
+	}
+	return utilerrors.NewAggregate(validationErrs)
+}
+
+func (r *componentGlobalsRegistry) EmulationChangedFeatureDefaults() map[string][]Feature {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	changed := make(map[string][]Feature, len(r.emulationChangedFeatureDefaults))
+	for comp, features := range r.emulationChangedFeatureDefaults {
+		changed[comp] = append([]Feature{}, features...)
+	}
+	return changed
+}
+
+// FeatureDefaultDiff returns the features of the component's feature gate whose default or
+// availability changes between the two versions, mapped to their defaults at [from, to].
+func (r *componentGlobalsRegistry) FeatureDefaultDiff(component string, from, to *version.Version) map[Feature][2]bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	globals, ok := r.componentGlobals[component]
+	if !ok || globals.featureGate == nil || from == nil || to == nil {
+		return nil
+	}
+	diff := map[Feature][2]bool{}
+	for name, specs := range globals.featureGate.GetAllVersioned() {
+		fromDefault, okFrom := featureDefaultAtVersion(specs, from)
+		toDefault, okTo := featureDefaultAtVersion(specs, to)
+		if okFrom == okTo && fromDefault == toDefault {
+			continue
+		}
+		diff[name] = [2]bool{fromDefault, toDefault}
+	}
+	return diff
+}
+
+// featureDefaultsChangedBetween returns the features of the gate whose default at the emulation
+// version differs from their default at the binary version, sorted by name.
+func featureDefaultsChangedBetween(featureGate MutableVersionedFeatureGate, binaryVer, emulationVer *version.Version) []Feature {
+	if binaryVer == nil || emulationVer == nil || binaryVer.EqualTo(emulationVer) {
+		return nil
+	}
+	var changed []Feature
+	for name, specs := range featureGate.GetAllVersioned() {
+		binaryDefault, okBinary := featureDefaultAtVersion(specs, binaryVer)
+		emulationDefault, okEmulation := featureDefaultAtVersion(specs, emulationVer)
+		if okBinary && okEmulation && binaryDefault != emulationDefault {
+			changed = append(changed, name)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i] < changed[j] })
+	return changed
+}
+
+// featureDefaultAtVersion returns the default of the feature at the given version, i.e. the
+// default of the last spec whose version is at most v, and whether such a spec exists.
+func featureDefaultAtVersion(specs VersionedSpecs, v *version.Version) (bool, bool) {
+	def := false
+	found := false
+	for _, spec := range specs {
+		if spec.Version.GreaterThan(v) {
+			break
+		}
+		def = spec.Default
+		found = true
+	}
+	return def, found
 }
 
 func (r *componentGlobalsRegistry) Validate() []error {
 	var errs []error
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	for _, globals := range r.componentGlobals {
-		errs = append(errs, globals.effectiveVersion.Validate()...)
+	errs = append(errs, r.registrationErrs...)
+	for component, globals := range r.componentGlobals {
+		// the effective version and feature gate do not know which component they belong
+		// to, so their errors are prefixed here to keep multi-component failures actionable.
+		for _, err := range globals.effectiveVersion.Validate() {
+			errs = append(errs, fmt.Errorf("component %q: %w", component, err))
+		}
 		if globals.featureGate != nil {
-			errs = append(errs, globals.featureGate.Validate()...)
+			for _, err := range globals.featureGate.Validate() {
+				errs = append(errs, fmt.Errorf("component %q: %w", component, err))
+			}
+			// cross-check the gate against the component's effective version: a gate
+			// emulating a version outside the allowed range would serve feature behavior
+			// the effective version does not allow.
+			gateVersion := globals.featureGate.EmulationVersion()
+			minVer, maxVer := globals.effectiveVersion.AllowedEmulationVersionRange()
+			if gateVersion != nil && (gateVersion.LessThan(minVer) || gateVersion.GreaterThan(maxVer)) {
+				errs = append(errs, fmt.Errorf("emulation version %s of the feature gate of component %s is outside the allowed range [%s, %s]",
+					gateVersion.String(), component, minVer.String(), maxVer.String()))
+			}
+		}
+	}
+	return errs
+}
+
+func (r *componentGlobalsRegistry) AssertAllFeatureGatesSynced() []error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	components := make([]string, 0, len(r.componentGlobals))
+	for component := range r.componentGlobals {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	var errs []error
+	for _, component := range components {
+		globals := r.componentGlobals[component]
+		if globals.featureGate == nil {
+			continue
+		}
+		gateVersion := globals.featureGate.EmulationVersion()
+		componentVersion := globals.effectiveVersion.EmulationVersion()
+		if gateVersion == nil {
+			errs = append(errs, fmt.Errorf("the feature gate of component %s has no emulation version while the component is at %s",
+				component, componentVersion.String()))
+			continue
+		}
+		if !gateVersion.EqualTo(componentVersion) {
+			errs = append(errs, fmt.Errorf("emulation version %s of the feature gate of component %s does not match the emulation version %s of the component",
+				gateVersion.String(), component, componentVersion.String()))
 		}
 	}
 	return errs
@@ -425,10 +1095,10 @@ func (r *componentGlobalsRegistry) SetEmulationVersionMapping(fromComponent, toC
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	if _, ok := r.componentGlobals[fromComponent]; !ok {
-		return fmt.Errorf("component not registered: %s", fromComponent)
+		return fmt.Errorf("%w: %s", ErrComponentNotRegistered, fromComponent)
 	}
 	if _, ok := r.componentGlobals[toComponent]; !ok {
-		return fmt.Errorf("component not registered: %s", toComponent)
+		return fmt.Errorf("%w: %s", ErrComponentNotRegistered, toComponent)
 	}
 	// check multiple dependency
 	if r.componentGlobals[toComponent].dependentEmulationVersion {
@@ -452,3 +1122,136 @@ func (r *componentGlobalsRegistry) SetEmulationVersionMapping(fromComponent, toC
 	}
 	return nil
 }
+
+// SetEmulationVersionMappings sets several emulation version mappings atomically.
+// The whole batch is validated before anything is applied, so a failure midway cannot
+// leave the registry with only part of the batch.
+func (r *componentGlobalsRegistry) SetEmulationVersionMappings(mappings []Mapping) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	// validate the whole batch against the current state before applying anything.
+	newTargets := sets.NewString()
+	edges := map[string][]string{}
+	for fromComponent, globals := range r.componentGlobals {
+		for toComponent := range globals.emulationVersionMapping {
+			edges[fromComponent] = append(edges[fromComponent], toComponent)
+		}
+	}
+	for _, mapping := range mappings {
+		if mapping.F == nil {
+			return fmt.Errorf("mapping from %s to %s has no mapping function", mapping.FromComponent, mapping.ToComponent)
+		}
+		if _, ok := r.componentGlobals[mapping.FromComponent]; !ok {
+			return fmt.Errorf("%w: %s", ErrComponentNotRegistered, mapping.FromComponent)
+		}
+		if _, ok := r.componentGlobals[mapping.ToComponent]; !ok {
+			return fmt.Errorf("%w: %s", ErrComponentNotRegistered, mapping.ToComponent)
+		}
+		if r.componentGlobals[mapping.ToComponent].dependentEmulationVersion || newTargets.Has(mapping.ToComponent) {
+			return fmt.Errorf("mapping of %s already exists from another component", mapping.ToComponent)
+		}
+		newTargets.Insert(mapping.ToComponent)
+		edges[mapping.FromComponent] = append(edges[mapping.FromComponent], mapping.ToComponent)
+	}
+	if hasEmulationMappingCycle(edges) {
+		return fmt.Errorf("setting the batch of mappings would create a %w", ErrVersionMappingLoop)
+	}
+
+	for _, mapping := range mappings {
+		klog.V(klogLevel).Infof("setting EmulationVersion mapping from %s to %s", mapping.FromComponent, mapping.ToComponent)
+		r.componentGlobals[mapping.ToComponent].dependentEmulationVersion = true
+		r.componentGlobals[mapping.FromComponent].emulationVersionMapping[mapping.ToComponent] = mapping.F
+	}
+	// propagate the default EmulationVersions through the new mappings, starting from the
+	// components that are not themselves determined by a mapping.
+	roots := sets.NewString()
+	for _, mapping := range mappings {
+		if !r.componentGlobals[mapping.FromComponent].dependentEmulationVersion {
+			roots.Insert(mapping.FromComponent)
+		}
+	}
+	for _, root := range roots.List() {
+		defaultFromVersion := r.componentGlobals[root].effectiveVersion.EmulationVersion()
+		emulationVersions, err := r.getFullEmulationVersionConfig(map[string]*version.Version{root: defaultFromVersion})
+		if err != nil {
+			return err
+		}
+		for comp, ver := range emulationVersions {
+			r.componentGlobals[comp].effectiveVersion.SetEmulationVersion(ver)
+		}
+	}
+	return nil
+}
+
+// hasEmulationMappingCycle reports whether the mapping edges contain a cycle.
+func hasEmulationMappingCycle(edges map[string][]string) bool {
+	const (
+		inProgress = 1
+		done       = 2
+	)
+	state := map[string]int{}
+	var visit func(node string) bool
+	visit = func(node string) bool {
+		switch state[node] {
+		case inProgress:
+			return true
+		case done:
+			return false
+		}
+		state[node] = inProgress
+		for _, next := range edges[node] {
+			if visit(next) {
+				return true
+			}
+		}
+		state[node] = done
+		return false
+	}
+	for node := range edges {
+		if visit(node) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *componentGlobalsRegistry) SetEmulationVersionMappingN(fromComponents []string, toComponent string, f MultiVersionMapping) error {
+	if f == nil {
+		return nil
+	}
+	klog.V(klogLevel).Infof("setting EmulationVersion mapping from %v to %s", fromComponents, toComponent)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(fromComponents) == 0 {
+		return fmt.Errorf("mapping to %s must have at least one source component", toComponent)
+	}
+	defaultFromVersions := map[string]*version.Version{}
+	for _, fromComponent := range fromComponents {
+		if _, ok := r.componentGlobals[fromComponent]; !ok {
+			return fmt.Errorf("%w: %s", ErrComponentNotRegistered, fromComponent)
+		}
+		defaultFromVersions[fromComponent] = r.componentGlobals[fromComponent].effectiveVersion.EmulationVersion()
+	}
+	if _, ok := r.componentGlobals[toComponent]; !ok {
+		return fmt.Errorf("%w: %s", ErrComponentNotRegistered, toComponent)
+	}
+	// check multiple dependency against both single-source and multi-source mappings.
+	if r.componentGlobals[toComponent].dependentEmulationVersion {
+		return fmt.Errorf("mapping of %s already exists from another component", toComponent)
+	}
+	r.componentGlobals[toComponent].dependentEmulationVersion = true
+	r.multiEmulationVersionMappings[toComponent] = multiVersionMapping{
+		fromComponents: append([]string{}, fromComponents...),
+		f:              f,
+	}
+
+	klog.V(klogLevel).Infof("setting the default EmulationVersion of %s based on mapping from the default EmulationVersions of %v", toComponent, fromComponents)
+	emulationVersions, err := r.getFullEmulationVersionConfig(defaultFromVersions)
+	if err != nil {
+		return err
+	}
+	for comp, ver := range emulationVersions {
+		r.componentGlobals[comp].effectiveVersion.SetEmulationVersion(ver)
+	}
+	return nil
+}
@@ -17,13 +17,16 @@ limitations under the License.
 package featuregate
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/spf13/pflag"
 
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/version"
 	baseversion "k8s.io/component-base/version"
 )
@@ -55,6 +58,56 @@ func TestEffectiveVersionRegistry(t *testing.T) {
 	}
 }
 
+func TestNewComponentGlobalsRegistryWithComponents(t *testing.T) {
+	ver1 := baseversion.NewEffectiveVersion("1.31")
+	ver2 := baseversion.NewEffectiveVersion("2.8")
+	r := NewComponentGlobalsRegistry(
+		WithComponent(DefaultKubeComponent, ver1, NewVersionedFeatureGate(version.MustParse("0.0"))),
+		WithComponent(testComponent, ver2, nil),
+	)
+	if errs := r.Validate(); len(errs) > 0 {
+		t.Fatalf("expected no registration errors, got: %v", errs)
+	}
+	if !r.EffectiveVersionFor(DefaultKubeComponent).EqualTo(ver1) {
+		t.Errorf("expected EffectiveVersionFor to return the version registered for the kube component")
+	}
+	if !r.EffectiveVersionFor(testComponent).EqualTo(ver2) {
+		t.Errorf("expected EffectiveVersionFor to return the version registered for the test component")
+	}
+
+	// duplicate registration surfaces through Validate.
+	r = NewComponentGlobalsRegistry(
+		WithComponent(testComponent, ver1, nil),
+		WithComponent(testComponent, ver2, nil),
+	)
+	if errs := r.Validate(); len(errs) != 1 {
+		t.Errorf("expected 1 registration error for duplicate component, got: %v", errs)
+	}
+}
+
+func TestRegisterDefaultKubeComponent(t *testing.T) {
+	r := NewComponentGlobalsRegistry()
+	fg := NewVersionedFeatureGate(version.MustParse("0.0"))
+	ver, gate := RegisterDefaultKubeComponent(r, fg)
+
+	registeredVer := r.EffectiveVersionFor(DefaultKubeComponent)
+	if registeredVer == nil {
+		t.Fatalf("expected the kube component to be registered")
+	}
+	if !registeredVer.BinaryVersion().EqualTo(baseversion.DefaultBuildEffectiveVersion().BinaryVersion()) {
+		t.Errorf("expected kube binary version %s, got %s",
+			baseversion.DefaultBuildEffectiveVersion().BinaryVersion().String(), registeredVer.BinaryVersion().String())
+	}
+	if gate != fg {
+		t.Errorf("expected the provided feature gate to be registered")
+	}
+	// registering again returns the existing globals.
+	ver2, gate2 := RegisterDefaultKubeComponent(r, NewVersionedFeatureGate(version.MustParse("0.0")))
+	if ver2 != ver || gate2 != gate {
+		t.Errorf("expected the existing kube component globals to be returned")
+	}
+}
+
 func testRegistry(t *testing.T) *componentGlobalsRegistry {
 	r := NewComponentGlobalsRegistry()
 	verKube := baseversion.NewEffectiveVersion("1.31")
@@ -311,6 +364,74 @@ func TestFlags(t *testing.T) {
 	}
 }
 
+func TestRegisterAlias(t *testing.T) {
+	r := NewComponentGlobalsRegistry()
+	utilruntime.Must(r.Register("test1", baseversion.NewEffectiveVersion("1.31"), nil))
+	utilruntime.Must(r.Register("test2", baseversion.NewEffectiveVersion("1.31"), nil))
+
+	if err := r.RegisterAlias("test1", "test2"); err == nil {
+		t.Errorf("expected error registering an alias conflicting with a component name")
+	}
+	if err := r.RegisterAlias("apiservers", "test3"); err == nil {
+		t.Errorf("expected error registering an alias with an unregistered member")
+	}
+	utilruntime.Must(r.RegisterAlias("apiservers", "test1", "test2"))
+	if err := r.RegisterAlias("apiservers", "test1"); err == nil {
+		t.Errorf("expected error registering an alias twice")
+	}
+
+	fs := pflag.NewFlagSet("testflag", pflag.ContinueOnError)
+	r.AddFlags(fs)
+	if err := fs.Parse([]string{"--emulated-version=apiservers=1.30"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Set(); err != nil {
+		t.Fatal(err)
+	}
+	assertVersionEqualTo(t, r.EffectiveVersionFor("test1").EmulationVersion(), "1.30")
+	assertVersionEqualTo(t, r.EffectiveVersionFor("test2").EmulationVersion(), "1.30")
+}
+
+func TestRegisterAliasDuplicateMember(t *testing.T) {
+	r := NewComponentGlobalsRegistry()
+	utilruntime.Must(r.Register("test1", baseversion.NewEffectiveVersion("1.31"), nil))
+	utilruntime.Must(r.Register("test2", baseversion.NewEffectiveVersion("1.31"), nil))
+	utilruntime.Must(r.RegisterAlias("apiservers", "test1", "test2"))
+
+	fs := pflag.NewFlagSet("testflag", pflag.ContinueOnError)
+	r.AddFlags(fs)
+	if err := fs.Parse([]string{"--emulated-version=apiservers=1.30,test1=1.31"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Set(); err == nil || !strings.Contains(err.Error(), "duplicate version flag") {
+		t.Errorf("expected duplicate version flag error, got: %v", err)
+	}
+}
+
+func TestAddFlagsAfterReset(t *testing.T) {
+	r := testRegistry(t)
+	fs := pflag.NewFlagSet("testflag", pflag.ContinueOnError)
+	r.AddFlags(fs)
+
+	r.Reset()
+	// Reset clears the stored flag configs, so the next AddFlags should not hit
+	// the duplicate AddFlags warning condition.
+	if r.emulationVersionConfig != nil || r.featureGatesConfig != nil {
+		t.Fatalf("expected Reset to clear the flag configs")
+	}
+
+	utilruntime.Must(r.Register(DefaultKubeComponent, baseversion.NewEffectiveVersion("1.31"), nil))
+	fs = pflag.NewFlagSet("testflag", pflag.ContinueOnError)
+	r.AddFlags(fs)
+	if err := fs.Parse([]string{"--emulated-version=kube=1.30"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Set(); err != nil {
+		t.Fatal(err)
+	}
+	assertVersionEqualTo(t, r.EffectiveVersionFor(DefaultKubeComponent).EmulationVersion(), "1.30")
+}
+
 func TestVersionMapping(t *testing.T) {
 	r := NewComponentGlobalsRegistry()
 	ver1 := baseversion.NewEffectiveVersion("0.58")
@@ -353,6 +474,45 @@ func TestVersionMapping(t *testing.T) {
 	assertVersionEqualTo(t, r.EffectiveVersionFor("test3").EmulationVersion(), "2.09")
 }
 
+func TestMultiSourceVersionMapping(t *testing.T) {
+	minVersion := func(froms map[string]*version.Version) *version.Version {
+		var min *version.Version
+		for _, ver := range froms {
+			if min == nil || ver.LessThan(min) {
+				min = ver
+			}
+		}
+		return min
+	}
+
+	r := NewComponentGlobalsRegistry()
+	utilruntime.Must(r.Register("test1", baseversion.NewEffectiveVersion("1.31"), nil))
+	utilruntime.Must(r.Register("test2", baseversion.NewEffectiveVersion("1.30"), nil))
+	utilruntime.Must(r.Register("test3", baseversion.NewEffectiveVersion("1.31"), nil))
+
+	utilruntime.Must(r.SetEmulationVersionMappingN([]string{"test1", "test2"}, "test3", minVersion))
+	// the default of the target is resolved from the defaults of the sources.
+	assertVersionEqualTo(t, r.EffectiveVersionFor("test3").EmulationVersion(), "1.30")
+
+	// the target cannot have another mapping or be set from the flag.
+	if err := r.SetEmulationVersionMapping("test1", "test3", func(from *version.Version) *version.Version { return from }); err == nil {
+		t.Errorf("expected conflict error adding a single-source mapping to a multi-source target")
+	}
+
+	fs := pflag.NewFlagSet("testflag", pflag.ContinueOnError)
+	r.AddFlags(fs)
+	if err := fs.Parse([]string{"--emulated-version=test1=1.29"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Set(); err != nil {
+		t.Fatal(err)
+	}
+	// test2 keeps its default of 1.30, so min(1.29, 1.30) = 1.29.
+	assertVersionEqualTo(t, r.EffectiveVersionFor("test1").EmulationVersion(), "1.29")
+	assertVersionEqualTo(t, r.EffectiveVersionFor("test2").EmulationVersion(), "1.30")
+	assertVersionEqualTo(t, r.EffectiveVersionFor("test3").EmulationVersion(), "1.29")
+}
+
 func TestVersionMappingWithMultipleDependency(t *testing.T) {
 	r := NewComponentGlobalsRegistry()
 	ver1 := baseversion.NewEffectiveVersion("0.58")
@@ -417,3 +577,565 @@ func assertVersionEqualTo(t *testing.T, ver *version.Version, expectedVer string
 	}
 	t.Errorf("expected: %s, got %s", expectedVer, ver.String())
 }
+
+func TestSetEmulationVersionConfigConflict(t *testing.T) {
+	tests := []struct {
+		name       string
+		flagValue  string
+		fileConfig []string
+		expectErr  bool
+		expectKube string
+	}{
+		{
+			name:       "flag and file agree",
+			flagValue:  "--emulated-version=kube=1.30",
+			fileConfig: []string{"kube=1.30"},
+			expectKube: "1.30",
+		},
+		{
+			name:       "flag and file disagree",
+			flagValue:  "--emulated-version=kube=1.31",
+			fileConfig: []string{"kube=1.30"},
+			expectErr:  true,
+		},
+		{
+			name:       "file only",
+			fileConfig: []string{"kube=1.30"},
+			expectKube: "1.30",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := testRegistry(t)
+			fs := pflag.NewFlagSet("testflag", pflag.ContinueOnError)
+			r.AddFlags(fs)
+			args := []string{}
+			if test.flagValue != "" {
+				args = append(args, test.flagValue)
+			}
+			if err := fs.Parse(args); err != nil {
+				t.Fatal(err)
+			}
+			r.SetEmulationVersionConfig("file", test.fileConfig)
+
+			err := r.Set()
+			if test.expectErr {
+				if err == nil {
+					t.Fatal("expected conflict error, got none")
+				}
+				if !strings.Contains(err.Error(), "1.31") || !strings.Contains(err.Error(), "1.30") {
+					t.Errorf("expected error to name both versions, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertVersionEqualTo(t, r.EffectiveVersionFor(DefaultKubeComponent).EmulationVersion(), test.expectKube)
+		})
+	}
+}
+
+func TestSetDefaultFeatureGateComponent(t *testing.T) {
+	tests := []struct {
+		name      string
+		flags     []string
+		expectErr bool
+	}{
+		{
+			name:  "unprefixed gate applies to the configured default component",
+			flags: []string{"--feature-gates=testA=true"},
+		},
+		{
+			name:      "mixing empty and explicit prefix for the default component",
+			flags:     []string{"--feature-gates=testA=true", fmt.Sprintf("--feature-gates=%s:testB=true", testComponent)},
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := testRegistry(t)
+			r.SetDefaultFeatureGateComponent(testComponent)
+			fs := pflag.NewFlagSet("testflag", pflag.ContinueOnError)
+			r.AddFlags(fs)
+			if err := fs.Parse(test.flags); err != nil {
+				t.Fatal(err)
+			}
+
+			err := r.Set()
+			if test.expectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !r.FeatureGateFor(testComponent).Enabled("testA") {
+				t.Errorf("expected testA to be enabled for %s", testComponent)
+			}
+		})
+	}
+}
+
+func TestSetVersionsProgrammatic(t *testing.T) {
+	r := testRegistry(t)
+	utilruntime.Must(r.SetEmulationVersionMapping(testComponent, DefaultKubeComponent,
+		func(from *version.Version) *version.Version { return version.MajorMinor(1, from.Minor()+23) }))
+
+	err := r.SetVersions(
+		map[string]string{testComponent: "2.7"},
+		map[string][]string{testComponent: {"testA=true"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertVersionEqualTo(t, r.EffectiveVersionFor(testComponent).EmulationVersion(), "2.7")
+	// the dependent mapping resolves like the flag-driven Set().
+	assertVersionEqualTo(t, r.EffectiveVersionFor(DefaultKubeComponent).EmulationVersion(), "1.30")
+	if !r.FeatureGateFor(testComponent).Enabled("testA") {
+		t.Errorf("expected testA to be enabled for %s", testComponent)
+	}
+
+	// setting the version of a dependent component directly is still rejected.
+	r = testRegistry(t)
+	utilruntime.Must(r.SetEmulationVersionMapping(testComponent, DefaultKubeComponent,
+		func(from *version.Version) *version.Version { return version.MajorMinor(1, from.Minor()+23) }))
+	if err := r.SetVersions(map[string]string{DefaultKubeComponent: "1.30"}, nil); err == nil {
+		t.Fatal("expected error setting a dependent component version, got none")
+	}
+}
+
+func TestEmulationChangedFeatureDefaults(t *testing.T) {
+	r := testRegistry(t)
+	if err := r.SetVersions(map[string]string{DefaultKubeComponent: "1.29"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// kubeA defaults to true at the 1.31 binary version but false at emulation version 1.29.
+	expected := map[string][]Feature{
+		DefaultKubeComponent: {"kubeA"},
+	}
+	if actual := r.EmulationChangedFeatureDefaults(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("EmulationChangedFeatureDefaults() = %v, want %v", actual, expected)
+	}
+
+	// nothing is reported when no emulation is in effect.
+	r = testRegistry(t)
+	if err := r.SetVersions(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if actual := r.EmulationChangedFeatureDefaults(); len(actual) != 0 {
+		t.Errorf("expected no changed defaults, got %v", actual)
+	}
+}
+
+func TestValidateProposedEmulationVersions(t *testing.T) {
+	r := testRegistry(t)
+
+	// an in-range proposal yields no errors and leaves the registry untouched.
+	errs := r.ValidateProposedEmulationVersions(map[string]string{DefaultKubeComponent: "1.29", testComponent: "2.8"})
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors for an in-range proposal, got %v", errs)
+	}
+	assertVersionEqualTo(t, r.EffectiveVersionFor(DefaultKubeComponent).EmulationVersion(), "1.31")
+
+	// a proposal below the kube component's floor yields one error naming the component.
+	errs = r.ValidateProposedEmulationVersions(map[string]string{DefaultKubeComponent: "1.27", testComponent: "2.8"})
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly one error for an out-of-range proposal, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), DefaultKubeComponent) {
+		t.Errorf("expected the error to name the component, got %v", errs[0])
+	}
+
+	// a component determined by mapping cannot be proposed directly.
+	utilruntime.Must(r.SetEmulationVersionMapping(testComponent, DefaultKubeComponent,
+		func(from *version.Version) *version.Version { return version.MajorMinor(1, from.Minor()+23) }))
+	errs = r.ValidateProposedEmulationVersions(map[string]string{DefaultKubeComponent: "1.30"})
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly one error proposing a dependent component version, got %v", errs)
+	}
+}
+
+func TestResolveEmulationVersion(t *testing.T) {
+	r := testRegistry(t)
+	utilruntime.Must(r.SetEmulationVersionMapping(testComponent, DefaultKubeComponent,
+		func(from *version.Version) *version.Version { return version.MajorMinor(1, from.Minor()+23) }))
+
+	resolved, err := r.ResolveEmulationVersion(testComponent, "2.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]*version.Version{
+		testComponent:        version.MajorMinor(2, 7),
+		DefaultKubeComponent: version.MajorMinor(1, 30),
+	}
+	if len(resolved) != len(expected) {
+		t.Fatalf("ResolveEmulationVersion() = %v, want %v", resolved, expected)
+	}
+	for comp, ver := range expected {
+		if actual, ok := resolved[comp]; !ok || !actual.EqualTo(ver) {
+			t.Errorf("resolved version of %s = %v, want %s", comp, resolved[comp], ver.String())
+		}
+	}
+	// the registry itself is left untouched.
+	assertVersionEqualTo(t, r.EffectiveVersionFor(testComponent).EmulationVersion(), "2.8")
+	assertVersionEqualTo(t, r.EffectiveVersionFor(DefaultKubeComponent).EmulationVersion(), "1.31")
+
+	// a component determined by mapping cannot be resolved directly.
+	if _, err := r.ResolveEmulationVersion(DefaultKubeComponent, "1.30"); err == nil {
+		t.Error("expected error resolving a dependent component version, got none")
+	}
+}
+
+func TestSetRevalidatesFeatureGates(t *testing.T) {
+	// testB is PreAlpha at emulation version 2.7, so enabling it must fail validation.
+	r := testRegistry(t)
+	if err := r.SetVersions(map[string]string{testComponent: "2.7"}, map[string][]string{testComponent: {"testB=true"}}); err == nil {
+		t.Fatal("expected a validation error enabling a feature unknown at the emulation version, got none")
+	}
+
+	// the same value is accepted at an emulation version where the feature exists.
+	r = testRegistry(t)
+	if err := r.SetVersions(nil, map[string][]string{testComponent: {"testA=true"}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDependentEmulationComponents(t *testing.T) {
+	r := testRegistry(t)
+	if components := r.DependentEmulationComponents(); len(components) != 0 {
+		t.Fatalf("expected no dependent components before any mapping, got %v", components)
+	}
+
+	utilruntime.Must(r.SetEmulationVersionMapping(testComponent, DefaultKubeComponent,
+		func(from *version.Version) *version.Version { return version.MajorMinor(1, from.Minor()+23) }))
+	expected := []string{DefaultKubeComponent}
+	if actual := r.DependentEmulationComponents(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("DependentEmulationComponents() = %v, want %v", actual, expected)
+	}
+	// no min compatibility version mappings are registered.
+	if components := r.DependentMinCompatibilityComponents(); len(components) != 0 {
+		t.Errorf("expected no dependent min compatibility components, got %v", components)
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	t.Run("patch version not allowed", func(t *testing.T) {
+		r := testRegistry(t)
+		_, err := r.ResolveEmulationVersion(testComponent, "2.7.3")
+		if !errors.Is(err, ErrPatchVersionNotAllowed) {
+			t.Errorf("expected error to match ErrPatchVersionNotAllowed, got: %v", err)
+		}
+	})
+	t.Run("component not registered", func(t *testing.T) {
+		r := testRegistry(t)
+		_, err := r.getFullEmulationVersionConfig(map[string]*version.Version{"no-such-component": version.MajorMinor(1, 0)})
+		if !errors.Is(err, ErrComponentNotRegistered) {
+			t.Errorf("expected error to match ErrComponentNotRegistered, got: %v", err)
+		}
+	})
+	t.Run("version mapping loop", func(t *testing.T) {
+		r := testRegistry(t)
+		utilruntime.Must(r.SetEmulationVersionMapping(testComponent, DefaultKubeComponent,
+			func(from *version.Version) *version.Version { return from.SubtractMinor(1) }))
+		// setting both the source and the target of the mapping queues the target twice.
+		_, err := r.getFullEmulationVersionConfig(map[string]*version.Version{
+			testComponent:        version.MajorMinor(2, 7),
+			DefaultKubeComponent: version.MajorMinor(1, 30),
+		})
+		if !errors.Is(err, ErrVersionMappingLoop) {
+			t.Errorf("expected error to match ErrVersionMappingLoop, got: %v", err)
+		}
+	})
+}
+
+func TestKnownFeaturesForComponent(t *testing.T) {
+	r := testRegistry(t)
+	known, err := r.KnownFeatures(testComponent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(known) == 0 {
+		t.Fatal("expected known features for the test component")
+	}
+	for _, f := range known {
+		if strings.Contains(f, ":") {
+			t.Errorf("expected known feature without component prefix, got %q", f)
+		}
+	}
+
+	if _, err := r.KnownFeatures("no-such-component"); !errors.Is(err, ErrComponentNotRegistered) {
+		t.Errorf("expected ErrComponentNotRegistered for unregistered component, got: %v", err)
+	}
+}
+
+func TestFeatureDefaultDiff(t *testing.T) {
+	r := testRegistry(t)
+
+	diff := r.FeatureDefaultDiff(DefaultKubeComponent, version.MajorMinor(1, 28), version.MajorMinor(1, 31))
+	expected := map[Feature][2]bool{
+		// default flips to true when the feature goes GA at 1.31.
+		"kubeA": {false, true},
+		// default flips to true when the feature goes beta at 1.29.
+		"commonC": {false, true},
+		// not available at 1.28, introduced as alpha at 1.30.
+		"kubeB": {false, false},
+	}
+	if !reflect.DeepEqual(expected, diff) {
+		t.Errorf("expected diff %v, got %v", expected, diff)
+	}
+
+	if diff := r.FeatureDefaultDiff(DefaultKubeComponent, version.MajorMinor(1, 30), version.MajorMinor(1, 30)); len(diff) != 0 {
+		t.Errorf("expected empty diff between identical versions, got %v", diff)
+	}
+	if diff := r.FeatureDefaultDiff("no-such-component", version.MajorMinor(1, 28), version.MajorMinor(1, 31)); diff != nil {
+		t.Errorf("expected nil diff for unregistered component, got %v", diff)
+	}
+}
+
+func TestRegisterFeatureGateFollowing(t *testing.T) {
+	r := testRegistry(t)
+	fg := NewVersionedFeatureGate(version.MustParse("0.0"))
+	err := fg.AddVersioned(map[Feature]VersionedSpecs{
+		"followerA": {
+			{Version: version.MustParse("1.27"), Default: false, PreRelease: Alpha},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.RegisterFeatureGateFollowing("follower", "no-such-component", fg); !errors.Is(err, ErrComponentNotRegistered) {
+		t.Errorf("expected ErrComponentNotRegistered following an unregistered component, got: %v", err)
+	}
+
+	if err := r.RegisterFeatureGateFollowing("follower", DefaultKubeComponent, fg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertVersionEqualTo(t, r.EffectiveVersionFor("follower").EmulationVersion(), "1.31")
+
+	// the follower tracks the followed component and cannot be set independently.
+	if err := r.SetVersions(map[string]string{DefaultKubeComponent: "1.29"}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertVersionEqualTo(t, r.EffectiveVersionFor("follower").EmulationVersion(), "1.29")
+
+	if err := r.SetVersions(map[string]string{"follower": "1.30"}, nil); err == nil {
+		t.Error("expected error setting the emulation version of a following component directly")
+	}
+}
+
+func TestSetEmulationVersionMappings(t *testing.T) {
+	r := testRegistry(t)
+	// the last mapping in the batch closes a cycle, so none of the batch should take effect.
+	err := r.SetEmulationVersionMappings([]Mapping{
+		{FromComponent: DefaultKubeComponent, ToComponent: testComponent,
+			F: func(from *version.Version) *version.Version { return version.MajorMinor(2, from.Minor()-23) }},
+		{FromComponent: testComponent, ToComponent: DefaultKubeComponent,
+			F: func(from *version.Version) *version.Version { return version.MajorMinor(1, from.Minor()+23) }},
+	})
+	if !errors.Is(err, ErrVersionMappingLoop) {
+		t.Fatalf("expected ErrVersionMappingLoop, got %v", err)
+	}
+	for _, comp := range []string{DefaultKubeComponent, testComponent} {
+		if r.componentGlobals[comp].dependentEmulationVersion {
+			t.Errorf("expected %s to not be a dependent component after a failed batch", comp)
+		}
+		if len(r.componentGlobals[comp].emulationVersionMapping) > 0 {
+			t.Errorf("expected no mapping from %s after a failed batch", comp)
+		}
+	}
+	assertVersionEqualTo(t, r.EffectiveVersionFor(DefaultKubeComponent).EmulationVersion(), "1.31")
+	assertVersionEqualTo(t, r.EffectiveVersionFor(testComponent).EmulationVersion(), "2.8")
+
+	// a valid batch is applied as a whole, and the default of the target is propagated.
+	utilruntime.Must(r.SetEmulationVersionMappings([]Mapping{
+		{FromComponent: DefaultKubeComponent, ToComponent: testComponent,
+			F: func(from *version.Version) *version.Version { return version.MajorMinor(2, from.Minor()-24) }},
+	}))
+	if !r.componentGlobals[testComponent].dependentEmulationVersion {
+		t.Errorf("expected %s to be a dependent component after a successful batch", testComponent)
+	}
+	assertVersionEqualTo(t, r.EffectiveVersionFor(testComponent).EmulationVersion(), "2.7")
+}
+
+func TestEmulationVersionFlagValue(t *testing.T) {
+	r := testRegistry(t)
+	if actual := r.EmulationVersionFlagValue(false); actual != "kube=1.31,test=2.8" {
+		t.Errorf("EmulationVersionFlagValue(false) = %q, want %q", actual, "kube=1.31,test=2.8")
+	}
+
+	utilruntime.Must(r.SetEmulationVersionMapping(DefaultKubeComponent, testComponent,
+		func(from *version.Version) *version.Version { return version.MajorMinor(2, from.Minor()-24) }))
+	// the derived component can no longer be set from the flag.
+	if actual := r.EmulationVersionFlagValue(false); actual != "kube=1.31" {
+		t.Errorf("EmulationVersionFlagValue(false) = %q, want %q", actual, "kube=1.31")
+	}
+	if actual := r.EmulationVersionFlagValue(true); actual != "kube=1.31,test=2.7" {
+		t.Errorf("EmulationVersionFlagValue(true) = %q, want %q", actual, "kube=1.31,test=2.7")
+	}
+}
+
+func TestRegistryForTest(t *testing.T) {
+	newIsolatedRegistry := func(emulationVersion string) ComponentGlobalsRegistry {
+		r := RegistryForTest()
+		verKube := baseversion.NewEffectiveVersion("1.31")
+		fgKube := NewVersionedFeatureGate(version.MustParse("0.0"))
+		utilruntime.Must(r.Register(DefaultKubeComponent, verKube, fgKube))
+		if err := r.SetVersions(map[string]string{DefaultKubeComponent: emulationVersion}, nil); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	r1 := newIsolatedRegistry("1.29")
+	r2 := newIsolatedRegistry("1.31")
+	// the registries are isolated: setting one does not leak into the other.
+	assertVersionEqualTo(t, r1.EffectiveVersionFor(DefaultKubeComponent).EmulationVersion(), "1.29")
+	assertVersionEqualTo(t, r2.EffectiveVersionFor(DefaultKubeComponent).EmulationVersion(), "1.31")
+	if r1.EffectiveVersionFor(DefaultKubeComponent) == DefaultComponentGlobalsRegistry.EffectiveVersionFor(DefaultKubeComponent) {
+		t.Errorf("expected the isolated registry to not share state with the default registry")
+	}
+}
+
+func TestValidateGateEmulationVersionRange(t *testing.T) {
+	r := testRegistry(t)
+	if errs := r.Validate(); len(errs) > 0 {
+		t.Fatalf("expected no errors from a fresh registry, got %v", errs)
+	}
+
+	// push the gate below the floor of the component's effective version, bypassing the registry.
+	fg := r.componentGlobals[DefaultKubeComponent].featureGate
+	utilruntime.Must(fg.SetEmulationVersion(version.MustParse("1.27")))
+	errs := r.Validate()
+	if len(errs) == 0 {
+		t.Fatal("expected an error for a gate emulation version below the floor, got none")
+	}
+	if !strings.Contains(errs[0].Error(), "outside the allowed range") {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+}
+
+func TestEmulationVersionMappings(t *testing.T) {
+	r := testRegistry(t)
+	verThird := baseversion.NewEffectiveVersion("3.5")
+	fgThird := NewVersionedFeatureGate(version.MustParse("0.0"))
+	utilruntime.Must(r.Register("third", verThird, fgThird))
+
+	if actual := r.EmulationVersionMappings(); len(actual) != 0 {
+		t.Errorf("expected no mappings in a fresh registry, got %v", actual)
+	}
+
+	utilruntime.Must(r.SetEmulationVersionMapping(DefaultKubeComponent, testComponent,
+		func(from *version.Version) *version.Version { return version.MajorMinor(2, from.Minor()-24) }))
+	utilruntime.Must(r.SetEmulationVersionMapping(DefaultKubeComponent, "third",
+		func(from *version.Version) *version.Version { return version.MajorMinor(3, from.Minor()-27) }))
+
+	expected := map[string][]string{DefaultKubeComponent: {testComponent, "third"}}
+	if actual := r.EmulationVersionMappings(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("EmulationVersionMappings() = %v, want %v", actual, expected)
+	}
+}
+
+func TestChainVersionMapping(t *testing.T) {
+	subtractOne := func(from *version.Version) *version.Version { return from.SubtractMinor(1) }
+	clampTo30 := func(from *version.Version) *version.Version {
+		if from.LessThan(version.MajorMinor(1, 30)) {
+			return version.MajorMinor(1, 30)
+		}
+		return from
+	}
+
+	chained := ChainVersionMapping(subtractOne, clampTo30)
+	assertVersionEqualTo(t, chained(version.MajorMinor(1, 33)), "1.32")
+	assertVersionEqualTo(t, chained(version.MajorMinor(1, 30)), "1.30")
+
+	// a nil result short-circuits the rest of the chain.
+	var reached bool
+	toNil := func(from *version.Version) *version.Version { return nil }
+	record := func(from *version.Version) *version.Version { reached = true; return from }
+	if actual := ChainVersionMapping(toNil, record)(version.MajorMinor(1, 33)); actual != nil {
+		t.Errorf("expected nil from a short-circuited chain, got %s", actual.String())
+	}
+	if reached {
+		t.Errorf("expected the chain to short-circuit before the second mapping")
+	}
+
+	// nil mappings are skipped.
+	assertVersionEqualTo(t, ChainVersionMapping(nil, subtractOne)(version.MajorMinor(1, 33)), "1.32")
+}
+
+func TestValidateComponentContext(t *testing.T) {
+	r := testRegistry(t)
+
+	// push both components' emulation versions out of range, bypassing the registry.
+	r.componentGlobals[DefaultKubeComponent].effectiveVersion.SetEmulationVersion(version.MustParse("1.20"))
+	r.componentGlobals[testComponent].effectiveVersion.SetEmulationVersion(version.MustParse("2.1"))
+
+	errs := r.Validate()
+	if len(errs) == 0 {
+		t.Fatal("expected errors for out-of-range emulation versions, got none")
+	}
+	componentsSeen := sets.NewString()
+	for _, err := range errs {
+		for _, component := range []string{DefaultKubeComponent, testComponent} {
+			if strings.Contains(err.Error(), fmt.Sprintf("component %q", component)) {
+				componentsSeen.Insert(component)
+			}
+		}
+	}
+	if !componentsSeen.HasAll(DefaultKubeComponent, testComponent) {
+		t.Errorf("expected errors naming both components, saw %v in %v", componentsSeen.List(), errs)
+	}
+}
+
+func TestAssertAllFeatureGatesSynced(t *testing.T) {
+	r := testRegistry(t)
+	if errs := r.AssertAllFeatureGatesSynced(); len(errs) > 0 {
+		t.Fatalf("expected all gates to be synced after registration, got %v", errs)
+	}
+
+	// desync the gate from its component, bypassing the registry.
+	fg := r.componentGlobals[testComponent].featureGate
+	utilruntime.Must(fg.SetEmulationVersion(version.MustParse("2.7")))
+
+	errs := r.AssertAllFeatureGatesSynced()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error for a desynced gate, got %v", errs)
+	}
+	if expected := "emulation version 2.7 of the feature gate of component test does not match the emulation version 2.8 of the component"; errs[0].Error() != expected {
+		t.Errorf("expected %q, got %q", expected, errs[0].Error())
+	}
+}
+
+func TestResolvedFeatureSpec(t *testing.T) {
+	r := NewComponentGlobalsRegistry()
+	ver := baseversion.NewEffectiveVersion("1.30")
+	fg := NewVersionedFeatureGate(version.MustParse("0.0"))
+	utilruntime.Must(fg.AddVersioned(map[Feature]VersionedSpecs{
+		"featureA": {
+			{Version: version.MustParse("1.29"), Default: true, PreRelease: Beta},
+			{Version: version.MustParse("1.31"), Default: true, LockToDefault: true, PreRelease: GA},
+		},
+	}))
+	utilruntime.Must(r.Register(DefaultKubeComponent, ver, fg))
+
+	// at emulation version 1.30, the 1.29 spec governs; the 1.31 spec is not reached yet.
+	spec, ok := r.ResolvedFeatureSpec(DefaultKubeComponent, "featureA")
+	if !ok {
+		t.Fatal("expected featureA to be known")
+	}
+	if !spec.Version.EqualTo(version.MajorMinor(1, 29)) || spec.PreRelease != Beta || !spec.Default {
+		t.Errorf("expected the 1.29 beta spec to govern at emulation version 1.30, got %+v", spec)
+	}
+
+	if _, ok := r.ResolvedFeatureSpec(DefaultKubeComponent, "unknown"); ok {
+		t.Error("expected an unknown feature to not resolve")
+	}
+	if _, ok := r.ResolvedFeatureSpec("unregistered", "featureA"); ok {
+		t.Error("expected an unregistered component to not resolve")
+	}
+}
@@ -19,28 +19,77 @@ package version
 import (
 	"fmt"
 	"runtime"
+	"strings"
 	"sync/atomic"
 
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/version"
 	apimachineryversion "k8s.io/apimachinery/pkg/version"
+	"k8s.io/klog/v2"
 )
 
 var minimumKubeEmulationVersion *version.Version = version.MajorMinor(1, 31)
 
 type EffectiveVersion interface {
 	BinaryVersion() *version.Version
+	// BinaryMajorMinor returns the binary version stripped to major.minor, or nil if the
+	// binary version is unset.
+	BinaryMajorMinor() *version.Version
 	EmulationVersion() *version.Version
+	// RawEmulationVersion returns the stored emulation version without the pre-release
+	// overlay applied by EmulationVersion. For internal and test use only, e.g. to observe
+	// whether an alpha emulation version was configured.
+	RawEmulationVersion() *version.Version
+	// AllowedEmulationVersionRange returns the inclusive range of emulation versions
+	// accepted by Validate() for the binary version.
+	AllowedEmulationVersionRange() (min, max *version.Version)
 	MinCompatibilityVersion() *version.Version
 	EqualTo(other EffectiveVersion) bool
+	// EqualToIgnoringMinCompat is like EqualTo, but only compares the binary and emulation
+	// versions, for callers that intentionally vary the min compatibility version.
+	EqualToIgnoringMinCompat(other EffectiveVersion) bool
+	// Compare totally orders effective versions by binary, then emulation, then min
+	// compatibility version (major.minor each), returning -1, 0 or +1.
+	Compare(other EffectiveVersion) int
 	String() string
+	// DetailedString is like String, but includes the version.Info embedded in the
+	// binary version (git commit, build date) when present.
+	DetailedString() string
+	// HumanReadable returns a multi-line form of the effective version suitable for
+	// user-facing --version output.
+	HumanReadable() string
 	Validate() []error
+	// Advisories returns non-fatal notes about unusual but valid version combinations,
+	// e.g. a minCompatibilityVersion far below the binary version while no emulation is in effect.
+	Advisories() []string
 }
 
 type MutableEffectiveVersion interface {
 	EffectiveVersion
 	Set(binaryVersion, emulationVersion, minCompatibilityVersion *version.Version)
 	SetEmulationVersion(emulationVersion *version.Version)
+	// SetEmulationVersionCeiling caps the upper bound of the allowed emulation version range
+	// below the binary version. A nil ceiling removes the cap.
+	SetEmulationVersionCeiling(ceiling *version.Version)
 	SetMinCompatibilityVersion(minCompatibilityVersion *version.Version)
+	// CopyFrom sets the binary, emulation and min-compatibility versions from other in one
+	// call, for synchronizing a component's effective version to match another without the
+	// intermediate inconsistent states of three separate setter calls.
+	CopyFrom(other EffectiveVersion)
+	// Clone returns a copy of the effective version that can be mutated independently of the original.
+	Clone() MutableEffectiveVersion
+	// Freeze makes all subsequent Set* calls no-ops with a logged warning, preventing
+	// accidental late mutation of a shared global. Freezing cannot be undone.
+	Freeze()
+	// WithNoAlphaEmulation makes Validate() reject an emulation version carrying an alpha
+	// pre-release. Intended for hardened production deployments that must not serve any
+	// alpha-introduced behavior; the inverse of the test capability that uses alpha emulation
+	// to continue serving expired APIs.
+	WithNoAlphaEmulation() MutableEffectiveVersion
+	// ToMap returns the effective version as a plain string map, suitable for storing in a ConfigMap.
+	ToMap() map[string]string
+	// FromMap populates the effective version from a map produced by ToMap, validating the result.
+	FromMap(data map[string]string) error
 }
 
 type effectiveVersion struct {
@@ -54,6 +103,13 @@ type effectiveVersion struct {
 	emulationVersion atomic.Pointer[version.Version]
 	// minCompatibilityVersion could only contain major and minor versions.
 	minCompatibilityVersion atomic.Pointer[version.Version]
+	// emulationVersionCeiling, if set, caps the upper bound of the allowed emulation version
+	// range below the binary version.
+	emulationVersionCeiling atomic.Pointer[version.Version]
+	// When true, Validate() rejects an emulation version carrying an alpha pre-release.
+	noAlphaEmulation atomic.Bool
+	// When true, all Set* calls are ignored with a logged warning.
+	frozen atomic.Bool
 }
 
 // Get returns the overall codebase version. It's for detecting
@@ -74,6 +130,18 @@ func Get() apimachineryversion.Info {
 	}
 }
 
+// GetEffective returns the overall codebase version along with the emulation and
+// min compatibility versions of the default build effective version. It's useful for
+// support bundles that need the build and emulation info in one place.
+func GetEffective() apimachineryversion.EffectiveInfo {
+	effective := DefaultBuildEffectiveVersion()
+	return apimachineryversion.EffectiveInfo{
+		Info:                    Get(),
+		EmulationVersion:        effective.EmulationVersion().String(),
+		MinCompatibilityVersion: effective.MinCompatibilityVersion().String(),
+	}
+}
+
 func (m *effectiveVersion) BinaryVersion() *version.Version {
 	if m.useDefaultBuildBinaryVersion.Load() {
 		return defaultBuildBinaryVersion()
@@ -81,6 +149,10 @@ func (m *effectiveVersion) BinaryVersion() *version.Version {
 	return m.binaryVersion.Load()
 }
 
+func (m *effectiveVersion) BinaryMajorMinor() *version.Version {
+	return majorMinor(m.BinaryVersion())
+}
+
 func (m *effectiveVersion) EmulationVersion() *version.Version {
 	ver := m.emulationVersion.Load()
 	if ver != nil {
@@ -91,6 +163,12 @@ func (m *effectiveVersion) EmulationVersion() *version.Version {
 	return ver
 }
 
+// RawEmulationVersion returns the stored emulation version without the pre-release overlay
+// applied by EmulationVersion. For internal and test use only.
+func (m *effectiveVersion) RawEmulationVersion() *version.Version {
+	return m.emulationVersion.Load()
+}
+
 func (m *effectiveVersion) MinCompatibilityVersion() *version.Version {
 	return m.minCompatibilityVersion.Load()
 }
@@ -99,6 +177,46 @@ func (m *effectiveVersion) EqualTo(other EffectiveVersion) bool {
 	return m.BinaryVersion().EqualTo(other.BinaryVersion()) && m.EmulationVersion().EqualTo(other.EmulationVersion()) && m.MinCompatibilityVersion().EqualTo(other.MinCompatibilityVersion())
 }
 
+func (m *effectiveVersion) EqualToIgnoringMinCompat(other EffectiveVersion) bool {
+	return m.BinaryVersion().EqualTo(other.BinaryVersion()) && m.EmulationVersion().EqualTo(other.EmulationVersion())
+}
+
+// compareMajorMinor totally orders two versions by their major.minor, with nil ordered first.
+func compareMajorMinor(a, b *version.Version) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+	a, b = majorMinor(a), majorMinor(b)
+	switch {
+	case a.LessThan(b):
+		return -1
+	case a.GreaterThan(b):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Compare totally orders effective versions by binary, then emulation, then min compatibility
+// version (major.minor each), returning -1, 0 or +1, e.g. for slices.SortFunc over the
+// versions reported by a fleet.
+func (m *effectiveVersion) Compare(other EffectiveVersion) int {
+	if c := compareMajorMinor(m.BinaryVersion(), other.BinaryVersion()); c != 0 {
+		return c
+	}
+	if c := compareMajorMinor(m.EmulationVersion(), other.EmulationVersion()); c != 0 {
+		return c
+	}
+	return compareMajorMinor(m.MinCompatibilityVersion(), other.MinCompatibilityVersion())
+}
+
 func (m *effectiveVersion) String() string {
 	if m == nil {
 		return "<nil>"
@@ -107,6 +225,42 @@ func (m *effectiveVersion) String() string {
 		m.BinaryVersion().String(), m.EmulationVersion().String(), m.MinCompatibilityVersion().String())
 }
 
+// DetailedString is like String, but also includes the version.Info embedded in the binary
+// version via WithInfo (git commit, build date) when present, for support bundles.
+// When the binary version carries no info, it is identical to String.
+func (m *effectiveVersion) DetailedString() string {
+	if m == nil {
+		return "<nil>"
+	}
+	info := m.BinaryVersion().Info()
+	// Info synthesizes the version fields even when no info was attached, so use the
+	// git commit to tell whether the binary version actually carried build info.
+	if info == nil || info.GitCommit == "" {
+		return m.String()
+	}
+	return fmt.Sprintf("{BinaryVersion: %s, GitCommit: %s, BuildDate: %s, EmulationVersion: %s, MinCompatibilityVersion: %s}",
+		m.BinaryVersion().String(), info.GitCommit, info.BuildDate, m.EmulationVersion().String(), m.MinCompatibilityVersion().String())
+}
+
+// HumanReadable returns a multi-line form of the effective version for user-facing --version output.
+// The emulation and min compatibility lines are only included when they deviate from the defaults
+// implied by the binary version, so a binary running without emulation prints a single line.
+func (m *effectiveVersion) HumanReadable() string {
+	if m == nil {
+		return "<nil>"
+	}
+	binaryVersion := m.BinaryVersion()
+	lines := []string{fmt.Sprintf("Binary: v%s", binaryVersion.String())}
+	binaryMajorMinor := majorMinor(binaryVersion)
+	if emulationVersion := m.EmulationVersion(); !majorMinor(emulationVersion).EqualTo(binaryMajorMinor) {
+		lines = append(lines, fmt.Sprintf("Emulation: v%s", emulationVersion.String()))
+	}
+	if minCompatibilityVersion := m.MinCompatibilityVersion(); !minCompatibilityVersion.EqualTo(binaryMajorMinor.SubtractMinor(1)) {
+		lines = append(lines, fmt.Sprintf("Min compatibility: v%s", minCompatibilityVersion.String()))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func majorMinor(ver *version.Version) *version.Version {
 	if ver == nil {
 		return ver
@@ -114,7 +268,77 @@ func majorMinor(ver *version.Version) *version.Version {
 	return version.MajorMinor(ver.Major(), ver.Minor())
 }
 
+// SubtractMinorClamped returns the version n minor versions back, never crossing
+// below major.0. Subtracting past the first minor of a major returns major.0
+// instead of underflowing into the previous major.
+func SubtractMinorClamped(v *version.Version, n int) *version.Version {
+	if v == nil {
+		return v
+	}
+	if n <= 0 {
+		return majorMinor(v)
+	}
+	if uint(n) >= v.Minor() {
+		return version.MajorMinor(v.Major(), 0)
+	}
+	return v.SubtractMinor(uint(n))
+}
+
+// DefaultEmulationVersionFor returns the default emulation version for the given binary version,
+// i.e. the binary version truncated to major.minor. Returns nil for a nil input.
+func DefaultEmulationVersionFor(binary *version.Version) *version.Version {
+	return majorMinor(binary)
+}
+
+// UpgradeCompatible reports whether rolling from the old binary to the new one keeps stored
+// data decodable: the min-compatibility version of the new binary must be at most the
+// emulation version of the old one. It encodes the skew policy in one place for upgrade
+// tooling; when incompatible, the returned reason says why.
+func UpgradeCompatible(oldVersion, newVersion EffectiveVersion) (bool, string) {
+	oldEmulation := majorMinor(oldVersion.EmulationVersion())
+	newMinCompatibility := majorMinor(newVersion.MinCompatibilityVersion())
+	if newMinCompatibility.GreaterThan(oldEmulation) {
+		return false, fmt.Sprintf("min-compatibility version %s of the new binary is after the emulation version %s of the old binary",
+			newMinCompatibility.String(), oldEmulation.String())
+	}
+	return true, ""
+}
+
+// MinCompatibilityForDowngradeRange returns the min-compatibility version for an operator
+// planning to support downgrading the given number of minors below the emulation version:
+// emulation minus minors, clamped up to the floor. It formalizes the "emulation minus k"
+// pattern, of which the default min-compatibility version is the minors=1 case.
+// Returns nil for a nil emulation version.
+func MinCompatibilityForDowngradeRange(emulation *version.Version, minors int, floor *version.Version) *version.Version {
+	if emulation == nil {
+		return nil
+	}
+	minCompatibility := SubtractMinorClamped(emulation, minors)
+	if floor != nil && minCompatibility.LessThan(floor) {
+		return majorMinor(floor)
+	}
+	return minCompatibility
+}
+
+// Freeze makes all subsequent Set* calls no-ops with a logged warning.
+// It prevents accidental late mutation of a shared global and cannot be undone.
+// Use Clone() to derive a mutable copy of a frozen version.
+func (m *effectiveVersion) Freeze() {
+	m.frozen.Store(true)
+}
+
+func (m *effectiveVersion) isFrozen(operation string) bool {
+	if !m.frozen.Load() {
+		return false
+	}
+	klog.Warningf("ignoring %s on a frozen effective version %s", operation, m.String())
+	return true
+}
+
 func (m *effectiveVersion) Set(binaryVersion, emulationVersion, minCompatibilityVersion *version.Version) {
+	if m.isFrozen("Set") {
+		return
+	}
 	m.binaryVersion.Store(binaryVersion)
 	m.useDefaultBuildBinaryVersion.Store(false)
 	m.emulationVersion.Store(majorMinor(emulationVersion))
@@ -122,16 +346,120 @@ func (m *effectiveVersion) Set(binaryVersion, emulationVersion, minCompatibility
 }
 
 func (m *effectiveVersion) SetEmulationVersion(emulationVersion *version.Version) {
+	if m.isFrozen("SetEmulationVersion") {
+		return
+	}
 	m.emulationVersion.Store(majorMinor(emulationVersion))
 	// set the default minCompatibilityVersion to be emulationVersion - 1
 	m.minCompatibilityVersion.Store(majorMinor(emulationVersion.SubtractMinor(1)))
 }
 
+// SetEmulationVersionCeiling caps the upper bound of the allowed emulation version range
+// below the binary version. A nil ceiling removes the cap.
+func (m *effectiveVersion) SetEmulationVersionCeiling(ceiling *version.Version) {
+	if m.isFrozen("SetEmulationVersionCeiling") {
+		return
+	}
+	m.emulationVersionCeiling.Store(majorMinor(ceiling))
+}
+
+// CopyFrom sets the binary, emulation and min-compatibility versions from other in one call.
+func (m *effectiveVersion) CopyFrom(other EffectiveVersion) {
+	if m.isFrozen("CopyFrom") {
+		return
+	}
+	m.Set(other.BinaryVersion(), other.EmulationVersion(), other.MinCompatibilityVersion())
+}
+
 // SetMinCompatibilityVersion should be called after SetEmulationVersion
 func (m *effectiveVersion) SetMinCompatibilityVersion(minCompatibilityVersion *version.Version) {
+	if m.isFrozen("SetMinCompatibilityVersion") {
+		return
+	}
 	m.minCompatibilityVersion.Store(majorMinor(minCompatibilityVersion))
 }
 
+func (m *effectiveVersion) Clone() MutableEffectiveVersion {
+	clone := &effectiveVersion{}
+	clone.useDefaultBuildBinaryVersion.Store(m.useDefaultBuildBinaryVersion.Load())
+	clone.binaryVersion.Store(m.binaryVersion.Load())
+	clone.emulationVersion.Store(m.emulationVersion.Load())
+	clone.minCompatibilityVersion.Store(m.minCompatibilityVersion.Load())
+	clone.emulationVersionCeiling.Store(m.emulationVersionCeiling.Load())
+	clone.noAlphaEmulation.Store(m.noAlphaEmulation.Load())
+	// the clone is an independent copy, so it is mutable even if the original is frozen.
+	return clone
+}
+
+// WithNoAlphaEmulation makes Validate() reject an emulation version carrying an alpha pre-release.
+// This is intended for hardened production deployments; by default alpha emulation is allowed so
+// tests can continue serving expired APIs.
+func (m *effectiveVersion) WithNoAlphaEmulation() MutableEffectiveVersion {
+	m.noAlphaEmulation.Store(true)
+	return m
+}
+
+const (
+	binaryVersionKey           = "binaryVersion"
+	emulationVersionKey        = "emulationVersion"
+	minCompatibilityVersionKey = "minCompatibilityVersion"
+)
+
+// ToMap returns the effective version as a plain string map, suitable for storing in a ConfigMap.
+func (m *effectiveVersion) ToMap() map[string]string {
+	return map[string]string{
+		binaryVersionKey:           m.BinaryVersion().String(),
+		emulationVersionKey:        m.EmulationVersion().String(),
+		minCompatibilityVersionKey: m.MinCompatibilityVersion().String(),
+	}
+}
+
+// FromMap populates the effective version from a map produced by ToMap.
+// The binary version is required; emulation and min compatibility versions default like
+// NewEffectiveVersion when absent. The result is validated before it replaces the receiver.
+func (m *effectiveVersion) FromMap(data map[string]string) error {
+	binaryVer, ok := data[binaryVersionKey]
+	if !ok {
+		return fmt.Errorf("missing required key %q", binaryVersionKey)
+	}
+	binaryVersion, err := version.ParseGeneric(binaryVer)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %v", binaryVersionKey, binaryVer, err)
+	}
+	emulationVersion := majorMinor(binaryVersion)
+	if emulationVer, ok := data[emulationVersionKey]; ok {
+		if emulationVersion, err = version.ParseGeneric(emulationVer); err != nil {
+			return fmt.Errorf("invalid %s %q: %v", emulationVersionKey, emulationVer, err)
+		}
+	}
+	minCompatibilityVersion := emulationVersion.SubtractMinor(1)
+	if minCompatibilityVer, ok := data[minCompatibilityVersionKey]; ok {
+		if minCompatibilityVersion, err = version.ParseGeneric(minCompatibilityVer); err != nil {
+			return fmt.Errorf("invalid %s %q: %v", minCompatibilityVersionKey, minCompatibilityVer, err)
+		}
+	}
+
+	parsed := &effectiveVersion{}
+	parsed.Set(binaryVersion, emulationVersion, minCompatibilityVersion)
+	if errs := parsed.Validate(); len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+	m.Set(binaryVersion, emulationVersion, minCompatibilityVersion)
+	return nil
+}
+
+// AllowedEmulationVersionRange returns the inclusive range of emulation versions
+// accepted by Validate() for the binary version. A set ceiling lowers the upper
+// bound below the binary version.
+func (m *effectiveVersion) AllowedEmulationVersionRange() (min, max *version.Version) {
+	binaryVersion := m.BinaryVersion().WithPatch(0)
+	max = binaryVersion
+	if ceiling := m.emulationVersionCeiling.Load(); ceiling != nil && ceiling.LessThan(max) {
+		max = ceiling
+	}
+	return SubtractMinorClamped(binaryVersion, 3), max
+}
+
 func (m *effectiveVersion) Validate() []error {
 	var errs []error
 	// Validate only checks the major and minor versions.
@@ -140,20 +468,45 @@ func (m *effectiveVersion) Validate() []error {
 	minCompatibilityVersion := m.minCompatibilityVersion.Load()
 
 	// emulationVersion can only be 1.{binaryMinor-3}...1.{binaryMinor}
-	maxEmuVer := binaryVersion
-	minEmuVer := binaryVersion.SubtractMinor(3)
+	minEmuVer, maxEmuVer := m.AllowedEmulationVersionRange()
 	if emulationVersion.GreaterThan(maxEmuVer) || emulationVersion.LessThan(minEmuVer) {
 		errs = append(errs, fmt.Errorf("emulation version %s is not between [%s, %s]", emulationVersion.String(), minEmuVer.String(), maxEmuVer.String()))
 	}
 	// minCompatibilityVersion can only be 1.{binaryMinor-3} to 1.{binaryMinor}
 	maxCompVer := emulationVersion
-	minCompVer := binaryVersion.SubtractMinor(4)
+	minCompVer := SubtractMinorClamped(binaryVersion, 4)
 	if minCompatibilityVersion.GreaterThan(maxCompVer) || minCompatibilityVersion.LessThan(minCompVer) {
 		errs = append(errs, fmt.Errorf("minCompatibilityVersion version %s is not between [%s, %s]", minCompatibilityVersion.String(), minCompVer.String(), maxCompVer.String()))
 	}
+	if m.noAlphaEmulation.Load() && strings.Contains(m.EmulationVersion().PreRelease(), "alpha") {
+		errs = append(errs, fmt.Errorf("emulation version %s carries an alpha pre-release, which is not allowed when alpha emulation is disabled", m.EmulationVersion().String()))
+	}
 	return errs
 }
 
+// Advisories returns non-fatal notes about unusual but valid version combinations.
+// Unlike Validate, none of the returned notes should prevent the component from starting.
+func (m *effectiveVersion) Advisories() []string {
+	var advisories []string
+	binaryVersion := m.BinaryVersion()
+	emulationVersion := m.emulationVersion.Load()
+	minCompatibilityVersion := m.minCompatibilityVersion.Load()
+	if binaryVersion == nil || emulationVersion == nil || minCompatibilityVersion == nil {
+		return advisories
+	}
+	binaryVersion = binaryVersion.WithPatch(0)
+	// when emulation is not in effect, the default minCompatibilityVersion is binary-1.
+	// A lower minCompatibilityVersion is valid, but widens the set of releases the storage
+	// must remain decodable by, which is usually unintentional.
+	if emulationVersion.EqualTo(binaryVersion) && binaryVersion.Major() == minCompatibilityVersion.Major() &&
+		minCompatibilityVersion.LessThan(binaryVersion.SubtractMinor(1)) {
+		advisories = append(advisories, fmt.Sprintf(
+			"minCompatibilityVersion %s is %d minors below the binary version %s; wide storage decoding enabled",
+			minCompatibilityVersion.String(), binaryVersion.Minor()-minCompatibilityVersion.Minor(), binaryVersion.String()))
+	}
+	return advisories
+}
+
 func newEffectiveVersion(binaryVersion *version.Version, useDefaultBuildBinaryVersion bool) MutableEffectiveVersion {
 	effective := &effectiveVersion{}
 	compatVersion := binaryVersion.SubtractMinor(1)
@@ -185,13 +538,163 @@ func DefaultBuildEffectiveVersion() MutableEffectiveVersion {
 	return newEffectiveVersion(binaryVersion, true)
 }
 
+// defaultKubeBinaryVersionOverride, when non-empty, replaces DefaultKubeBinaryVersion in
+// DefaultKubeEffectiveVersion. It is only set from SetDefaultKubeBinaryVersionForTest.
+var defaultKubeBinaryVersionOverride string
+
+// SetDefaultKubeBinaryVersionForTest overrides the default kube binary version used by
+// DefaultKubeEffectiveVersion and returns a cleanup restoring the original, so tests can
+// pin the default independent of the build constant.
+func SetDefaultKubeBinaryVersionForTest(v string) func() {
+	previous := defaultKubeBinaryVersionOverride
+	defaultKubeBinaryVersionOverride = v
+	return func() {
+		defaultKubeBinaryVersionOverride = previous
+	}
+}
+
 // DefaultKubeEffectiveVersion returns the MutableEffectiveVersion based on the
 // latest K8s release.
 func DefaultKubeEffectiveVersion() MutableEffectiveVersion {
-	binaryVersion := version.MustParse(DefaultKubeBinaryVersion).WithInfo(Get())
+	defaultKubeBinaryVersion := DefaultKubeBinaryVersion
+	if len(defaultKubeBinaryVersionOverride) > 0 {
+		defaultKubeBinaryVersion = defaultKubeBinaryVersionOverride
+	}
+	binaryVersion := version.MustParse(defaultKubeBinaryVersion).WithInfo(Get())
 	return newEffectiveVersion(binaryVersion, false)
 }
 
+// CompactEffectiveVersion wraps the three versions of an EffectiveVersion in the compact
+// "binary@emulation~mincompat" text form, implementing encoding.TextMarshaler and
+// encoding.TextUnmarshaler so it can be embedded directly in structured config.
+// On input, the emulation and min compatibility parts may be omitted and default the same
+// way the effective version constructors do: emulation to the binary major.minor, and min
+// compatibility to one minor below the emulation version.
+type CompactEffectiveVersion struct {
+	BinaryVersion           *version.Version
+	EmulationVersion        *version.Version
+	MinCompatibilityVersion *version.Version
+}
+
+// CompactFromEffectiveVersion returns the compact DTO for the effective version.
+func CompactFromEffectiveVersion(effectiveVersion EffectiveVersion) CompactEffectiveVersion {
+	return CompactEffectiveVersion{
+		BinaryVersion:           effectiveVersion.BinaryVersion(),
+		EmulationVersion:        effectiveVersion.EmulationVersion(),
+		MinCompatibilityVersion: effectiveVersion.MinCompatibilityVersion(),
+	}
+}
+
+// EffectiveVersion returns the MutableEffectiveVersion carrying the wrapped versions.
+func (c CompactEffectiveVersion) EffectiveVersion() MutableEffectiveVersion {
+	effective := &effectiveVersion{}
+	effective.Set(c.BinaryVersion, c.EmulationVersion, c.MinCompatibilityVersion)
+	return effective
+}
+
+func (c CompactEffectiveVersion) MarshalText() ([]byte, error) {
+	if c.BinaryVersion == nil {
+		return nil, fmt.Errorf("missing binary version")
+	}
+	out := c.BinaryVersion.String()
+	if c.EmulationVersion != nil {
+		out += "@" + c.EmulationVersion.String()
+	}
+	if c.MinCompatibilityVersion != nil {
+		out += "~" + c.MinCompatibilityVersion.String()
+	}
+	return []byte(out), nil
+}
+
+func (c *CompactEffectiveVersion) UnmarshalText(text []byte) error {
+	remainder := strings.TrimSpace(string(text))
+	if len(remainder) == 0 {
+		return fmt.Errorf("empty effective version")
+	}
+	minCompatibilityStr := ""
+	if before, after, found := strings.Cut(remainder, "~"); found {
+		remainder, minCompatibilityStr = before, after
+	}
+	binaryStr := remainder
+	emulationStr := ""
+	if before, after, found := strings.Cut(remainder, "@"); found {
+		binaryStr, emulationStr = before, after
+	}
+	binaryVersion, err := version.ParseGeneric(binaryStr)
+	if err != nil {
+		return fmt.Errorf("invalid binary version %q: %v", binaryStr, err)
+	}
+	emulationVersion := majorMinor(binaryVersion)
+	if len(emulationStr) > 0 {
+		if emulationVersion, err = version.ParseGeneric(emulationStr); err != nil {
+			return fmt.Errorf("invalid emulation version %q: %v", emulationStr, err)
+		}
+	}
+	minCompatibilityVersion := emulationVersion.SubtractMinor(1)
+	if len(minCompatibilityStr) > 0 {
+		if minCompatibilityVersion, err = version.ParseGeneric(minCompatibilityStr); err != nil {
+			return fmt.Errorf("invalid minCompatibilityVersion %q: %v", minCompatibilityStr, err)
+		}
+	}
+	c.BinaryVersion = binaryVersion
+	c.EmulationVersion = emulationVersion
+	c.MinCompatibilityVersion = minCompatibilityVersion
+	return nil
+}
+
+// EmulationVersionFromRelease parses a release name like "1.31" into the corresponding
+// emulation version. Patch or pre-release segments are rejected, as is a release below
+// the floor (if a floor is given).
+func EmulationVersionFromRelease(release string, floor *version.Version) (*version.Version, error) {
+	ver, err := version.Parse(release)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release %q: %v", release, err)
+	}
+	if ver.Patch() != 0 || len(ver.PreRelease()) > 0 {
+		return nil, fmt.Errorf("release %q must only specify major.minor version", release)
+	}
+	if floor != nil && ver.LessThan(floor) {
+		return nil, fmt.Errorf("release %q is below the minimum supported version %s", release, floor.String())
+	}
+	return majorMinor(ver), nil
+}
+
+// EmulationVersionFromServerInfo derives an emulation version from the version.Info
+// discovered from an upstream apiserver, so a downstream component can auto-align its
+// behavior to the server. The major.minor of the GitVersion is used; a result below the
+// floor (if a floor is given) is clamped up to the floor.
+func EmulationVersionFromServerInfo(info apimachineryversion.Info, floor *version.Version) (*version.Version, error) {
+	ver, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server gitVersion %q: %v", info.GitVersion, err)
+	}
+	if floor != nil && ver.LessThan(floor) {
+		return majorMinor(floor), nil
+	}
+	return majorMinor(ver), nil
+}
+
+// DefaultVersionsForBinary returns the default emulation and min compatibility versions for
+// the given binary version, applying the kube floors: the defaults never go below the
+// minimum supported kube emulation version (and one minor less for min compatibility).
+// It answers "given this binary, what would the effective version default to" without
+// constructing the full effective version.
+func DefaultVersionsForBinary(binaryVer string) (emulation, minCompat *version.Version, err error) {
+	binaryVersion, err := version.ParseGeneric(binaryVer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid binary version %q: %v", binaryVer, err)
+	}
+	emulation = majorMinor(binaryVersion)
+	minCompat = SubtractMinorClamped(emulation, 1)
+	if emulation.LessThan(minimumKubeEmulationVersion) {
+		emulation = minimumKubeEmulationVersion
+	}
+	if minimumMinCompat := minimumKubeEmulationVersion.SubtractMinor(1); minCompat.LessThan(minimumMinCompat) {
+		minCompat = minimumMinCompat
+	}
+	return emulation, minCompat, nil
+}
+
 // ValidateKubeEffectiveVersion validates the EmulationVersion is at least 1.31 and MinCompatibilityVersion
 // is at least 1.30 for kube components.
 func ValidateKubeEffectiveVersion(effectiveVersion EffectiveVersion) error {
@@ -17,9 +17,11 @@ limitations under the License.
 package version
 
 import (
+	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/util/version"
+	apimachineryversion "k8s.io/apimachinery/pkg/version"
 )
 
 func TestValidate(t *testing.T) {
@@ -133,6 +135,272 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestHumanReadable(t *testing.T) {
+	tests := []struct {
+		name                    string
+		binaryVersion           string
+		emulationVersion        string
+		minCompatibilityVersion string
+		expect                  string
+	}{
+		{
+			name:                    "no emulation prints a single line",
+			binaryVersion:           "v1.31.2",
+			emulationVersion:        "v1.31.0",
+			minCompatibilityVersion: "v1.30.0",
+			expect:                  "Binary: v1.31.2",
+		},
+		{
+			name:                    "emulating prints three lines",
+			binaryVersion:           "v1.31.2",
+			emulationVersion:        "v1.30.0",
+			minCompatibilityVersion: "v1.29.0",
+			expect:                  "Binary: v1.31.2\nEmulation: v1.30\nMin compatibility: v1.29",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			effective := &effectiveVersion{}
+			effective.Set(version.MustParseGeneric(test.binaryVersion),
+				version.MustParseGeneric(test.emulationVersion),
+				version.MustParseGeneric(test.minCompatibilityVersion))
+
+			if actual := effective.HumanReadable(); actual != test.expect {
+				t.Errorf("expected %q, got %q", test.expect, actual)
+			}
+		})
+	}
+}
+
+func TestBinaryMajorMinor(t *testing.T) {
+	effective := NewEffectiveVersion("1.33.2")
+	if actual := effective.BinaryMajorMinor(); !actual.EqualTo(version.MajorMinor(1, 33)) {
+		t.Errorf("expected 1.33, got %s", actual.String())
+	}
+
+	unset := &effectiveVersion{}
+	if actual := unset.BinaryMajorMinor(); actual != nil {
+		t.Errorf("expected nil for an unset binary version, got %s", actual.String())
+	}
+}
+
+func TestEqualToIgnoringMinCompat(t *testing.T) {
+	a := NewEffectiveVersion("1.33")
+	a.SetMinCompatibilityVersion(version.MajorMinor(1, 31))
+	b := NewEffectiveVersion("1.33")
+	b.SetMinCompatibilityVersion(version.MajorMinor(1, 32))
+
+	if a.EqualTo(b) {
+		t.Errorf("expected %s not to equal %s", a.String(), b.String())
+	}
+	if !a.EqualToIgnoringMinCompat(b) {
+		t.Errorf("expected %s to equal %s ignoring min compatibility", a.String(), b.String())
+	}
+
+	b.SetEmulationVersion(version.MajorMinor(1, 32))
+	if a.EqualToIgnoringMinCompat(b) {
+		t.Errorf("expected %s not to equal %s with different emulation versions", a.String(), b.String())
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	effective := NewEffectiveVersion("1.33")
+	effective.Freeze()
+
+	effective.SetEmulationVersion(version.MajorMinor(1, 32))
+	if !effective.EmulationVersion().EqualTo(version.MajorMinor(1, 33)) {
+		t.Errorf("expected emulation version 1.33 after freezing, got %s", effective.EmulationVersion().String())
+	}
+	effective.SetMinCompatibilityVersion(version.MajorMinor(1, 30))
+	if !effective.MinCompatibilityVersion().EqualTo(version.MajorMinor(1, 32)) {
+		t.Errorf("expected min compatibility version 1.32 after freezing, got %s", effective.MinCompatibilityVersion().String())
+	}
+
+	// a clone of a frozen version is mutable again.
+	clone := effective.Clone()
+	clone.SetEmulationVersion(version.MajorMinor(1, 32))
+	if !clone.EmulationVersion().EqualTo(version.MajorMinor(1, 32)) {
+		t.Errorf("expected clone emulation version 1.32, got %s", clone.EmulationVersion().String())
+	}
+}
+
+func TestWithNoAlphaEmulation(t *testing.T) {
+	newAlphaEmulation := func() *effectiveVersion {
+		effective := &effectiveVersion{}
+		effective.Set(version.MustParse("v1.32.0-alpha.1"),
+			version.MustParseGeneric("v1.32.0"),
+			version.MustParseGeneric("v1.31.0"))
+		return effective
+	}
+
+	if errs := newAlphaEmulation().Validate(); len(errs) > 0 {
+		t.Errorf("expected no errors without the option, errors found %+v", errs)
+	}
+	if errs := newAlphaEmulation().WithNoAlphaEmulation().Validate(); len(errs) == 0 {
+		t.Error("expected a validation error for alpha emulation, no errors found")
+	}
+}
+
+func TestDefaultEmulationVersionFor(t *testing.T) {
+	if actual := DefaultEmulationVersionFor(version.MustParseGeneric("v1.33.2")); !actual.EqualTo(version.MajorMinor(1, 33)) {
+		t.Errorf("expected 1.33, got %s", actual.String())
+	}
+	if actual := DefaultEmulationVersionFor(nil); actual != nil {
+		t.Errorf("expected nil for nil input, got %s", actual.String())
+	}
+}
+
+func TestDetailedString(t *testing.T) {
+	withoutInfo := &effectiveVersion{}
+	withoutInfo.Set(version.MustParseGeneric("v1.33.2"),
+		version.MustParseGeneric("v1.32.0"),
+		version.MustParseGeneric("v1.31.0"))
+	if e, a := withoutInfo.String(), withoutInfo.DetailedString(); e != a {
+		t.Errorf("expected DetailedString without info to equal String %q, got %q", e, a)
+	}
+
+	withInfo := &effectiveVersion{}
+	withInfo.Set(version.MustParseGeneric("v1.33.2").WithInfo(apimachineryversion.Info{GitCommit: "abc123", BuildDate: "2024-01-01"}),
+		version.MustParseGeneric("v1.32.0"),
+		version.MustParseGeneric("v1.31.0"))
+	detailed := withInfo.DetailedString()
+	if detailed == withInfo.String() {
+		t.Errorf("expected DetailedString to differ from String %q", detailed)
+	}
+	for _, want := range []string{"abc123", "2024-01-01"} {
+		if !strings.Contains(detailed, want) {
+			t.Errorf("expected DetailedString %q to contain %q", detailed, want)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	original := NewEffectiveVersion("1.34")
+	clone := original.Clone()
+	if !clone.EqualTo(original) {
+		t.Fatalf("expected clone %s to equal the original %s", clone.String(), original.String())
+	}
+
+	// mutating the clone must not affect the original.
+	clone.SetEmulationVersion(version.MajorMinor(1, 32))
+	if !original.EmulationVersion().EqualTo(version.MajorMinor(1, 34)) {
+		t.Errorf("expected original emulation version 1.34, got %s", original.EmulationVersion().String())
+	}
+	if !clone.EmulationVersion().EqualTo(version.MajorMinor(1, 32)) {
+		t.Errorf("expected clone emulation version 1.32, got %s", clone.EmulationVersion().String())
+	}
+
+	// mutating the original must not affect the clone.
+	original.SetMinCompatibilityVersion(version.MajorMinor(1, 30))
+	if !clone.MinCompatibilityVersion().EqualTo(version.MajorMinor(1, 31)) {
+		t.Errorf("expected clone min compatibility version 1.31, got %s", clone.MinCompatibilityVersion().String())
+	}
+}
+
+func TestToMapFromMap(t *testing.T) {
+	original := NewEffectiveVersion("1.33")
+	original.SetEmulationVersion(version.MajorMinor(1, 32))
+	original.SetMinCompatibilityVersion(version.MajorMinor(1, 31))
+
+	rehydrated := NewEffectiveVersion("")
+	if err := rehydrated.FromMap(original.ToMap()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rehydrated.EqualTo(original) {
+		t.Errorf("expected round-tripped version %s to equal the original %s", rehydrated.String(), original.String())
+	}
+}
+
+func TestFromMapErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]string
+	}{
+		{
+			name: "missing binary version",
+			data: map[string]string{"emulationVersion": "1.32"},
+		},
+		{
+			name: "unparseable binary version",
+			data: map[string]string{"binaryVersion": "not-a-version"},
+		},
+		{
+			name: "emulation version too far below binary",
+			data: map[string]string{"binaryVersion": "1.33", "emulationVersion": "1.20"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			effective := NewEffectiveVersion("")
+			if err := effective.FromMap(test.data); err == nil {
+				t.Errorf("expected error for %v, got none", test.data)
+			}
+		})
+	}
+}
+
+func TestGetEffective(t *testing.T) {
+	info := GetEffective()
+	if info.GitVersion != Get().GitVersion {
+		t.Errorf("expected build info GitVersion %s, got %s", Get().GitVersion, info.GitVersion)
+	}
+	effective := DefaultBuildEffectiveVersion()
+	if info.EmulationVersion != effective.EmulationVersion().String() {
+		t.Errorf("expected emulation version %s, got %s", effective.EmulationVersion().String(), info.EmulationVersion)
+	}
+	if info.MinCompatibilityVersion != effective.MinCompatibilityVersion().String() {
+		t.Errorf("expected min compatibility version %s, got %s", effective.MinCompatibilityVersion().String(), info.MinCompatibilityVersion)
+	}
+}
+
+func TestAdvisories(t *testing.T) {
+	tests := []struct {
+		name                    string
+		binaryVersion           string
+		emulationVersion        string
+		minCompatibilityVersion string
+		expectAdvisories        int
+	}{
+		{
+			name:                    "default gap produces no advisory",
+			binaryVersion:           "v1.34.0",
+			emulationVersion:        "v1.34.0",
+			minCompatibilityVersion: "v1.33.0",
+			expectAdvisories:        0,
+		},
+		{
+			name:                    "wide min compatibility gap without emulation produces advisory",
+			binaryVersion:           "v1.34.0",
+			emulationVersion:        "v1.34.0",
+			minCompatibilityVersion: "v1.31.0",
+			expectAdvisories:        1,
+		},
+		{
+			name:                    "wide gap while emulating is expected",
+			binaryVersion:           "v1.34.0",
+			emulationVersion:        "v1.32.0",
+			minCompatibilityVersion: "v1.31.0",
+			expectAdvisories:        0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			effective := &effectiveVersion{}
+			effective.Set(version.MustParseGeneric(test.binaryVersion),
+				version.MustParseGeneric(test.emulationVersion),
+				version.MustParseGeneric(test.minCompatibilityVersion))
+
+			advisories := effective.Advisories()
+			if len(advisories) != test.expectAdvisories {
+				t.Errorf("expected %d advisories, got %+v", test.expectAdvisories, advisories)
+			}
+		})
+	}
+}
+
 func TestValidateKubeEffectiveVersion(t *testing.T) {
 	tests := []struct {
 		name                    string
@@ -183,3 +451,517 @@ func TestValidateKubeEffectiveVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestSubtractMinorClamped(t *testing.T) {
+	tests := []struct {
+		name            string
+		version         string
+		n               int
+		expectedVersion string
+	}{
+		{
+			name:            "subtraction within a major",
+			version:         "1.31",
+			n:               3,
+			expectedVersion: "1.28",
+		},
+		{
+			name:            "subtraction to zero",
+			version:         "1.3",
+			n:               3,
+			expectedVersion: "1.0",
+		},
+		{
+			name:            "subtraction below zero clamps at major.0",
+			version:         "1.2",
+			n:               3,
+			expectedVersion: "1.0",
+		},
+		{
+			name:            "zero subtraction",
+			version:         "2.5",
+			n:               0,
+			expectedVersion: "2.5",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := SubtractMinorClamped(version.MustParseGeneric(test.version), test.n)
+			if !result.EqualTo(version.MustParseGeneric(test.expectedVersion)) {
+				t.Errorf("expected %s, got %s", test.expectedVersion, result.String())
+			}
+		})
+	}
+}
+
+func TestEmulationVersionFromRelease(t *testing.T) {
+	tests := []struct {
+		name            string
+		release         string
+		floor           string
+		expectedVersion string
+		expectErr       bool
+	}{
+		{
+			name:            "valid release",
+			release:         "1.31",
+			floor:           "1.30",
+			expectedVersion: "1.31",
+		},
+		{
+			name:            "valid release without floor",
+			release:         "v1.33",
+			expectedVersion: "1.33",
+		},
+		{
+			name:      "release below floor",
+			release:   "1.29",
+			floor:     "1.31",
+			expectErr: true,
+		},
+		{
+			name:      "patch-bearing release",
+			release:   "1.31.2",
+			floor:     "1.30",
+			expectErr: true,
+		},
+		{
+			name:      "pre-release",
+			release:   "1.31.0-alpha.1",
+			expectErr: true,
+		},
+		{
+			name:      "unparseable release",
+			release:   "latest",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var floor *version.Version
+			if test.floor != "" {
+				floor = version.MustParseGeneric(test.floor)
+			}
+			result, err := EmulationVersionFromRelease(test.release, floor)
+			if test.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s", result.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.EqualTo(version.MustParseGeneric(test.expectedVersion)) {
+				t.Errorf("expected %s, got %s", test.expectedVersion, result.String())
+			}
+		})
+	}
+}
+
+func TestCompactEffectiveVersionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name                     string
+		text                     string
+		expectedBinary           string
+		expectedEmulation        string
+		expectedMinCompatibility string
+		expectedText             string
+		expectErr                bool
+	}{
+		{
+			name:                     "full compact form",
+			text:                     "1.32.1@1.31~1.30",
+			expectedBinary:           "1.32.1",
+			expectedEmulation:        "1.31",
+			expectedMinCompatibility: "1.30",
+			expectedText:             "1.32.1@1.31~1.30",
+		},
+		{
+			name:                     "binary only",
+			text:                     "1.32.1",
+			expectedBinary:           "1.32.1",
+			expectedEmulation:        "1.32",
+			expectedMinCompatibility: "1.31",
+			expectedText:             "1.32.1@1.32~1.31",
+		},
+		{
+			name:                     "omitted min compatibility",
+			text:                     "1.32@1.31",
+			expectedBinary:           "1.32",
+			expectedEmulation:        "1.31",
+			expectedMinCompatibility: "1.30",
+			expectedText:             "1.32@1.31~1.30",
+		},
+		{
+			name:      "empty",
+			text:      "",
+			expectErr: true,
+		},
+		{
+			name:      "invalid emulation version",
+			text:      "1.32@latest",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compact := CompactEffectiveVersion{}
+			err := compact.UnmarshalText([]byte(test.text))
+			if test.expectErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !compact.BinaryVersion.EqualTo(version.MustParseGeneric(test.expectedBinary)) {
+				t.Errorf("expected binary version %s, got %s", test.expectedBinary, compact.BinaryVersion.String())
+			}
+			if !compact.EmulationVersion.EqualTo(version.MustParseGeneric(test.expectedEmulation)) {
+				t.Errorf("expected emulation version %s, got %s", test.expectedEmulation, compact.EmulationVersion.String())
+			}
+			if !compact.MinCompatibilityVersion.EqualTo(version.MustParseGeneric(test.expectedMinCompatibility)) {
+				t.Errorf("expected minCompatibilityVersion %s, got %s", test.expectedMinCompatibility, compact.MinCompatibilityVersion.String())
+			}
+
+			marshaled, err := compact.MarshalText()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(marshaled) != test.expectedText {
+				t.Errorf("expected text %q, got %q", test.expectedText, string(marshaled))
+			}
+			// the marshaled text must round-trip to the same versions.
+			roundTripped := CompactEffectiveVersion{}
+			if err := roundTripped.UnmarshalText(marshaled); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !roundTripped.EffectiveVersion().EqualTo(compact.EffectiveVersion()) {
+				t.Errorf("expected round-tripped effective version %s, got %s",
+					compact.EffectiveVersion().String(), roundTripped.EffectiveVersion().String())
+			}
+		})
+	}
+}
+
+func TestAllowedEmulationVersionRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		ceiling     string
+		expectedMin string
+		expectedMax string
+	}{
+		{
+			name:        "no ceiling",
+			expectedMin: "1.29",
+			expectedMax: "1.32",
+		},
+		{
+			name:        "ceiling below binary",
+			ceiling:     "1.30",
+			expectedMin: "1.29",
+			expectedMax: "1.30",
+		},
+		{
+			name:        "ceiling above binary",
+			ceiling:     "1.33",
+			expectedMin: "1.29",
+			expectedMax: "1.32",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			effective := NewEffectiveVersion("1.32")
+			if test.ceiling != "" {
+				effective.SetEmulationVersionCeiling(version.MustParseGeneric(test.ceiling))
+			}
+			minVer, maxVer := effective.AllowedEmulationVersionRange()
+			if !minVer.EqualTo(version.MustParseGeneric(test.expectedMin)) {
+				t.Errorf("expected min %s, got %s", test.expectedMin, minVer.String())
+			}
+			if !maxVer.EqualTo(version.MustParseGeneric(test.expectedMax)) {
+				t.Errorf("expected max %s, got %s", test.expectedMax, maxVer.String())
+			}
+		})
+	}
+}
+
+func TestDefaultVersionsForBinary(t *testing.T) {
+	tests := []struct {
+		name              string
+		binaryVer         string
+		expectedEmulation string
+		expectedMinCompat string
+		expectErr         bool
+	}{
+		{
+			name:              "recent binary",
+			binaryVer:         "v1.33.2",
+			expectedEmulation: "1.33",
+			expectedMinCompat: "1.32",
+		},
+		{
+			name:              "binary at the minimum emulation version",
+			binaryVer:         "1.31.0",
+			expectedEmulation: "1.31",
+			expectedMinCompat: "1.30",
+		},
+		{
+			name:              "binary below the minimum emulation version clamps to the floors",
+			binaryVer:         "1.30.5",
+			expectedEmulation: "1.31",
+			expectedMinCompat: "1.30",
+		},
+		{
+			name:      "invalid binary version",
+			binaryVer: "latest",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			emulation, minCompat, err := DefaultVersionsForBinary(test.binaryVer)
+			if test.expectErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !emulation.EqualTo(version.MustParseGeneric(test.expectedEmulation)) {
+				t.Errorf("expected emulation version %s, got %s", test.expectedEmulation, emulation.String())
+			}
+			if !minCompat.EqualTo(version.MustParseGeneric(test.expectedMinCompat)) {
+				t.Errorf("expected min compatibility version %s, got %s", test.expectedMinCompat, minCompat.String())
+			}
+		})
+	}
+}
+
+func TestRawEmulationVersion(t *testing.T) {
+	effective := &effectiveVersion{}
+	effective.binaryVersion.Store(version.MustParseSemantic("1.31.0-beta.1"))
+	// an alpha emulation version can be stored directly in tests to continue serving expired APIs.
+	effective.emulationVersion.Store(version.MustParseSemantic("1.31.0-alpha.3"))
+	effective.minCompatibilityVersion.Store(version.MajorMinor(1, 30))
+
+	if preRelease := effective.RawEmulationVersion().PreRelease(); preRelease != "alpha.3" {
+		t.Errorf("expected raw emulation pre-release alpha.3, got %q", preRelease)
+	}
+	// EmulationVersion overlays the binary's pre-release, hiding the stored alpha.
+	if preRelease := effective.EmulationVersion().PreRelease(); preRelease != "beta.1" {
+		t.Errorf("expected emulation pre-release beta.1, got %q", preRelease)
+	}
+}
+
+func TestEmulationVersionFromServerInfo(t *testing.T) {
+	tests := []struct {
+		name        string
+		gitVersion  string
+		floor       *version.Version
+		expected    string
+		expectError bool
+	}{
+		{
+			name:       "realistic git version",
+			gitVersion: "v1.31.2+abcdef",
+			expected:   "1.31",
+		},
+		{
+			name:       "below floor is clamped up",
+			gitVersion: "v1.28.0",
+			floor:      version.MajorMinor(1, 31),
+			expected:   "1.31",
+		},
+		{
+			name:       "at floor",
+			gitVersion: "v1.31.0",
+			floor:      version.MajorMinor(1, 31),
+			expected:   "1.31",
+		},
+		{
+			name:        "invalid git version",
+			gitVersion:  "not-a-version",
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := EmulationVersionFromServerInfo(apimachineryversion.Info{GitVersion: test.gitVersion}, test.floor)
+			if test.expectError {
+				if err == nil {
+					t.Fatalf("expected error for gitVersion %q, got none", test.gitVersion)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual.String() != test.expected {
+				t.Errorf("expected emulation version %s, got %s", test.expected, actual.String())
+			}
+		})
+	}
+}
+
+func TestEffectiveVersionCompare(t *testing.T) {
+	newEffectiveVersion := func(binary, emulation string) MutableEffectiveVersion {
+		ev := NewEffectiveVersion(binary)
+		ev.SetEmulationVersion(version.MustParse(emulation))
+		return ev
+	}
+	tests := []struct {
+		name     string
+		a        EffectiveVersion
+		b        EffectiveVersion
+		expected int
+	}{
+		{
+			name:     "equal",
+			a:        newEffectiveVersion("1.32", "1.31"),
+			b:        newEffectiveVersion("1.32", "1.31"),
+			expected: 0,
+		},
+		{
+			name:     "binary differs",
+			a:        newEffectiveVersion("1.31", "1.31"),
+			b:        newEffectiveVersion("1.32", "1.31"),
+			expected: -1,
+		},
+		{
+			name:     "emulation differs with equal binary",
+			a:        newEffectiveVersion("1.32", "1.32"),
+			b:        newEffectiveVersion("1.32", "1.31"),
+			expected: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := test.a.Compare(test.b); actual != test.expected {
+				t.Errorf("Compare() = %d, want %d", actual, test.expected)
+			}
+			if actual := test.b.Compare(test.a); actual != -test.expected {
+				t.Errorf("reversed Compare() = %d, want %d", actual, -test.expected)
+			}
+		})
+	}
+}
+
+func TestSetDefaultKubeBinaryVersionForTest(t *testing.T) {
+	original := DefaultKubeEffectiveVersion().BinaryVersion()
+
+	cleanup := SetDefaultKubeBinaryVersionForTest("1.99")
+	if actual := DefaultKubeEffectiveVersion().BinaryVersion(); !actual.EqualTo(version.MajorMinor(1, 99)) {
+		t.Errorf("expected pinned binary version 1.99, got %s", actual.String())
+	}
+
+	cleanup()
+	if actual := DefaultKubeEffectiveVersion().BinaryVersion(); !actual.EqualTo(original) {
+		t.Errorf("expected binary version %s after cleanup, got %s", original.String(), actual.String())
+	}
+}
+
+func TestMinCompatibilityForDowngradeRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		emulation *version.Version
+		minors    int
+		floor     *version.Version
+		expected  string
+	}{
+		{
+			name:      "one minor is the default min-compatibility version",
+			emulation: version.MajorMinor(1, 31),
+			minors:    1,
+			expected:  "1.30",
+		},
+		{
+			name:      "three minors",
+			emulation: version.MajorMinor(1, 31),
+			minors:    3,
+			expected:  "1.28",
+		},
+		{
+			name:      "clamped to the floor",
+			emulation: version.MajorMinor(1, 31),
+			minors:    3,
+			floor:     version.MajorMinor(1, 30),
+			expected:  "1.30",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := MinCompatibilityForDowngradeRange(test.emulation, test.minors, test.floor)
+			if actual.String() != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, actual.String())
+			}
+		})
+	}
+}
+
+func TestCopyFrom(t *testing.T) {
+	source := NewEffectiveVersion("1.32")
+	source.Set(version.MustParse("1.32.1"), version.MajorMinor(1, 31), version.MajorMinor(1, 30))
+
+	target := NewEffectiveVersion("1.28")
+	target.CopyFrom(source)
+
+	if actual := target.BinaryVersion().String(); actual != "1.32.1" {
+		t.Errorf("expected binary version 1.32.1, got %s", actual)
+	}
+	if actual := target.EmulationVersion().String(); actual != "1.31" {
+		t.Errorf("expected emulation version 1.31, got %s", actual)
+	}
+	if actual := target.MinCompatibilityVersion().String(); actual != "1.30" {
+		t.Errorf("expected min compatibility version 1.30, got %s", actual)
+	}
+	if !target.EqualTo(source) {
+		t.Errorf("expected target %s to equal source %s after CopyFrom", target.String(), source.String())
+	}
+}
+
+func TestUpgradeCompatible(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldVersion EffectiveVersion
+		newVersion EffectiveVersion
+		expected   bool
+	}{
+		{
+			name:       "one minor upgrade",
+			oldVersion: NewEffectiveVersion("1.31"),
+			newVersion: NewEffectiveVersion("1.32"),
+			expected:   true,
+		},
+		{
+			name:       "two minor jump",
+			oldVersion: NewEffectiveVersion("1.31"),
+			newVersion: NewEffectiveVersion("1.33"),
+			expected:   false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compatible, reason := UpgradeCompatible(test.oldVersion, test.newVersion)
+			if compatible != test.expected {
+				t.Errorf("expected compatible=%v, got %v (%s)", test.expected, compatible, reason)
+			}
+			if !compatible && len(reason) == 0 {
+				t.Error("expected a reason for an incompatible upgrade")
+			}
+			if compatible && len(reason) != 0 {
+				t.Errorf("expected no reason for a compatible upgrade, got %q", reason)
+			}
+		})
+	}
+}
@@ -17,11 +17,13 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
 	"sort"
@@ -31,11 +33,15 @@ import (
 	"github.com/spf13/cobra"
 
 	"k8s.io/apimachinery/pkg/util/version"
+	baseversion "k8s.io/component-base/version"
 	yaml "sigs.k8s.io/yaml/goyaml.v2"
 )
 
 var (
 	alphabeticalOrder          bool
+	minSupportedVersion        string
+	binaryVersion              string
+	gitRef                     string
 	k8RootPath                 string
 	unversionedFeatureListFile = "test/featuregates_linter/test_data/unversioned_feature_list.yaml"
 	versionedFeatureListFile   = "test/featuregates_linter/test_data/versioned_feature_list.yaml"
@@ -43,6 +49,9 @@ var (
 
 const (
 	featureGatePkg = "\"k8s.io/component-base/featuregate\""
+	// defaultProgressionWaiverMarker in a comment on or above a feature entry waives the
+	// default progression check for that feature.
+	defaultProgressionWaiverMarker = "default-progression-waiver"
 )
 
 type featureSpec struct {
@@ -56,6 +65,9 @@ type featureInfo struct {
 	Name           string        `yaml:"name" json:"name"`
 	FullName       string        `yaml:"-" json:"-"`
 	VersionedSpecs []featureSpec `yaml:"versionedSpecs" json:"versionedSpecs"`
+	// DefaultProgressionWaived is true when the feature entry carries a
+	// default-progression-waiver comment. It is not part of the feature list files.
+	DefaultProgressionWaived bool `yaml:"-" json:"-"`
 }
 
 // NewFeatureGatesCommand returns the cobra command for "feature-gates".
@@ -72,6 +84,9 @@ func NewFeatureGatesCommand() *cobra.Command {
 
 	cmd.AddCommand(NewVerifyFeatureListCommand())
 	cmd.AddCommand(NewUpdateFeatureListCommand())
+	cmd.AddCommand(NewVerifyNoOrphanedSpecsCommand())
+	cmd.AddCommand(NewVerifyDefaultProgressionCommand())
+	cmd.AddCommand(NewVerifyNoFutureSpecsCommand())
 	return cmd
 }
 
@@ -82,6 +97,7 @@ func NewVerifyFeatureListCommand() *cobra.Command {
 		Run:   verifyFeatureListFunc,
 	}
 	cmd.Flags().BoolVar(&alphabeticalOrder, "alphabetical-order", false, "if true, verify all features in any FeatureSpec map are ordered aphabetically")
+	cmd.Flags().StringVar(&gitRef, "git-ref", "", "if set, read the base feature list files from this git ref via `git show` instead of the working tree, e.g. origin/master")
 	return &cmd
 }
 
@@ -94,6 +110,56 @@ func NewUpdateFeatureListCommand() *cobra.Command {
 	return &cmd
 }
 
+func NewVerifyNoOrphanedSpecsCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "verify-no-orphaned-specs",
+		Short: "Verifies no feature has its entire VersionedSpecs history before the minimum supported version.",
+		Run:   verifyNoOrphanedSpecsFunc,
+	}
+	cmd.Flags().StringVar(&minSupportedVersion, "min-version", "", "minimum supported emulation version in major.minor format. Features whose latest spec version is older than this version should be removed or simplified.")
+	return &cmd
+}
+
+func NewVerifyDefaultProgressionCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "verify-default-progression",
+		Short: "Verifies no feature default regresses from true to false across versions in VersionedSpecs.",
+		Run:   verifyDefaultProgressionFunc,
+	}
+	return &cmd
+}
+
+func NewVerifyNoFutureSpecsCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "verify-no-future-specs",
+		Short: "Verifies no feature has a VersionedSpecs entry with a version newer than the binary version.",
+		Run:   verifyNoFutureSpecsFunc,
+	}
+	cmd.Flags().StringVar(&binaryVersion, "binary-version", baseversion.DefaultKubeBinaryVersion, "binary version in major.minor format that the spec versions are checked against.")
+	return &cmd
+}
+
+func verifyNoFutureSpecsFunc(cmd *cobra.Command, args []string) {
+	if err := verifyNoFutureSpecs(k8RootPath, binaryVersion); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to verify future versioned specs: \n%s", err)
+		os.Exit(1)
+	}
+}
+
+func verifyDefaultProgressionFunc(cmd *cobra.Command, args []string) {
+	if err := verifyDefaultProgression(k8RootPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to verify default progression: \n%s", err)
+		os.Exit(1)
+	}
+}
+
+func verifyNoOrphanedSpecsFunc(cmd *cobra.Command, args []string) {
+	if err := verifyNoOrphanedSpecs(k8RootPath, minSupportedVersion); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to verify orphaned versioned specs: \n%s", err)
+		os.Exit(1)
+	}
+}
+
 func verifyFeatureListFunc(cmd *cobra.Command, args []string) {
 	if err := verifyOrUpdateFeatureList(k8RootPath, unversionedFeatureListFile, false, false); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to verify feature list: \n%s", err)
@@ -142,7 +208,12 @@ func verifyOrUpdateFeatureList(rootPath, featureListFile string, update, version
 	}
 
 	filePath := filepath.Join(rootPath, featureListFile)
-	baseFeatureListBytes, err := os.ReadFile(filePath)
+	var baseFeatureListBytes []byte
+	if len(gitRef) > 0 && !update {
+		baseFeatureListBytes, err = readFileAtGitRef(rootPath, gitRef, featureListFile)
+	} else {
+		baseFeatureListBytes, err = os.ReadFile(filePath)
+	}
 	if err != nil {
 		return err
 	}
@@ -180,6 +251,148 @@ func verifyOrUpdateFeatureList(rootPath, featureListFile string, update, version
 	return nil
 }
 
+// execCommand is a hook for stubbing out the git command in tests.
+var execCommand = exec.Command
+
+// readFileAtGitRef returns the content of the file at the given git ref via `git show`,
+// so CI with a checked-out repo can compare against a ref without downloading anything.
+func readFileAtGitRef(rootPath, ref, path string) ([]byte, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git is required for --git-ref but was not found: %w", err)
+	}
+	out, err := execCommand("git", "-C", rootPath, "show", fmt.Sprintf("%s:%s", ref, path)).Output()
+	if err != nil {
+		msg := err.Error()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			msg = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("cannot read %s at git ref %s: %s", path, ref, msg)
+	}
+	return out, nil
+}
+
+// collectAllFeatures walks all the files under pkg/ and staging/ and returns the features in
+// map[featuregate.Feature]featuregate.VersionedSpecs, for the verify subcommands that check
+// properties of the versioned spec histories.
+func collectAllFeatures(rootPath string) ([]featureInfo, error) {
+	featureList := []featureInfo{}
+	features, err := searchPathForFeatures(filepath.Join(rootPath, "pkg"), true)
+	if err != nil {
+		return nil, err
+	}
+	featureList = append(featureList, features...)
+
+	features, err = searchPathForFeatures(filepath.Join(rootPath, "staging"), true)
+	if err != nil {
+		return nil, err
+	}
+	featureList = append(featureList, features...)
+	return featureList, nil
+}
+
+// verifyNoOrphanedSpecs walks all the files under pkg/ and staging/ to find features in
+// map[featuregate.Feature]featuregate.VersionedSpecs whose entire spec history predates minVersionStr.
+// Such a feature no longer changes behavior for any supported emulation version,
+// and can be unconditionally removed or simplified.
+func verifyNoOrphanedSpecs(rootPath, minVersionStr string) error {
+	minVersion, err := version.Parse(minVersionStr)
+	if err != nil {
+		return fmt.Errorf("invalid min-version %q: %w", minVersionStr, err)
+	}
+	featureList, err := collectAllFeatures(rootPath)
+	if err != nil {
+		return err
+	}
+
+	orphaned := []string{}
+	for _, f := range featureList {
+		if len(f.VersionedSpecs) == 0 {
+			continue
+		}
+		// VersionedSpecs are verified to be ordered by version, so the last spec is the latest lifecycle change.
+		latest, err := version.Parse(f.VersionedSpecs[len(f.VersionedSpecs)-1].Version)
+		if err != nil {
+			return fmt.Errorf("invalid version in VersionedSpecs of feature %s: %w", f.Name, err)
+		}
+		if latest.LessThan(minVersion) {
+			orphaned = append(orphaned, f.Name)
+		}
+	}
+	if len(orphaned) > 0 {
+		sort.Strings(orphaned)
+		return fmt.Errorf("features with all VersionedSpecs before the minimum supported version %s: %v\nPlease remove the features or collapse their spec history! ", minVersion.String(), orphaned)
+	}
+	return nil
+}
+
+// verifyNoFutureSpecs walks all the files under pkg/ and staging/ to find features in
+// map[featuregate.Feature]featuregate.VersionedSpecs with a spec version newer than
+// binaryVersionStr. Such a future-dated spec is almost certainly a mistake during a backport,
+// since the spec can never take effect in this binary.
+func verifyNoFutureSpecs(rootPath, binaryVersionStr string) error {
+	binaryVer, err := version.Parse(binaryVersionStr)
+	if err != nil {
+		return fmt.Errorf("invalid binary-version %q: %w", binaryVersionStr, err)
+	}
+	featureList, err := collectAllFeatures(rootPath)
+	if err != nil {
+		return err
+	}
+
+	futureDated := []string{}
+	for _, f := range featureList {
+		for _, spec := range f.VersionedSpecs {
+			specVer, err := version.Parse(spec.Version)
+			if err != nil {
+				return fmt.Errorf("invalid version in VersionedSpecs of feature %s: %w", f.Name, err)
+			}
+			if specVer.GreaterThan(binaryVer) {
+				futureDated = append(futureDated, f.Name)
+				break
+			}
+		}
+	}
+	if len(futureDated) > 0 {
+		sort.Strings(futureDated)
+		return fmt.Errorf("features with a VersionedSpecs entry newer than the binary version %s: %v\nPlease fix the spec versions! ", binaryVer.String(), futureDated)
+	}
+	return nil
+}
+
+// verifyDefaultProgression walks all the files under pkg/ and staging/ to find features in
+// map[featuregate.Feature]featuregate.VersionedSpecs whose default regresses from true back
+// to false at a later version. Such a regression usually signals a bug; intentional ones can
+// be waived with a default-progression-waiver comment on the feature entry.
+func verifyDefaultProgression(rootPath string) error {
+	featureList, err := collectAllFeatures(rootPath)
+	if err != nil {
+		return err
+	}
+
+	regressed := []string{}
+	for _, f := range featureList {
+		if f.DefaultProgressionWaived {
+			continue
+		}
+		// VersionedSpecs are verified to be ordered by version.
+		seenEnabled := false
+		for _, spec := range f.VersionedSpecs {
+			if spec.Default {
+				seenEnabled = true
+			} else if seenEnabled {
+				regressed = append(regressed, f.Name)
+				break
+			}
+		}
+	}
+	if len(regressed) > 0 {
+		sort.Strings(regressed)
+		return fmt.Errorf("features whose default regresses from true to false across versions: %v\nPlease fix the VersionedSpecs or add a %s comment on the feature entry! ", regressed, defaultProgressionWaiverMarker)
+	}
+	return nil
+}
+
 func dedupeFeatureList(featureList []featureInfo) ([]featureInfo, error) {
 	if featureList == nil || len(featureList) < 1 {
 		return featureList, nil
@@ -257,7 +470,7 @@ func extractFeatureInfoListFromFile(fset *token.FileSet, filePath string, versio
 	if err != nil {
 		return allFeatures, err
 	}
-	file, err := parser.ParseFile(fset, absFilePath, nil, parser.AllErrors)
+	file, err := parser.ParseFile(fset, absFilePath, nil, parser.AllErrors|parser.ParseComments)
 	if err != nil {
 		return allFeatures, err
 	}
@@ -267,6 +480,15 @@ func extractFeatureInfoListFromFile(fset *token.FileSet, filePath string, versio
 		return allFeatures, err
 	}
 	variables := globalVariableDeclarations(file)
+	// lines carrying a waiver comment, to match against the feature entries later.
+	waivedLines := map[int]bool{}
+	for _, commentGroup := range file.Comments {
+		for _, comment := range commentGroup.List {
+			if strings.Contains(comment.Text, defaultProgressionWaiverMarker) {
+				waivedLines[fset.Position(comment.End()).Line] = true
+			}
+		}
+	}
 
 	for _, d := range file.Decls {
 		if gd, ok := d.(*ast.GenDecl); ok && (gd.Tok == token.CONST || gd.Tok == token.VAR) {
@@ -274,7 +496,7 @@ func extractFeatureInfoListFromFile(fset *token.FileSet, filePath string, versio
 				if vspec, ok := spec.(*ast.ValueSpec); ok {
 					for _, name := range vspec.Names {
 						for _, value := range vspec.Values {
-							features, err := extractFeatureInfoList(filePath, value, aliasMap, variables, versioned)
+							features, err := extractFeatureInfoList(fset, filePath, value, aliasMap, variables, versioned, waivedLines)
 							if err != nil {
 								return allFeatures, err
 							}
@@ -291,7 +513,7 @@ func extractFeatureInfoListFromFile(fset *token.FileSet, filePath string, versio
 			for _, stmt := range fd.Body.List {
 				if st, ok := stmt.(*ast.ReturnStmt); ok {
 					for _, value := range st.Results {
-						features, err := extractFeatureInfoList(filePath, value, aliasMap, variables, versioned)
+						features, err := extractFeatureInfoList(fset, filePath, value, aliasMap, variables, versioned, waivedLines)
 						if err != nil {
 							return allFeatures, err
 						}
@@ -333,7 +555,7 @@ func verifyAlphabeticOrder(keys []string, path string) error {
 
 // extractFeatureInfoList extracts the info all the the features from
 // map[featuregate.Feature]featuregate.FeatureSpec or map[featuregate.Feature]featuregate.VersionedSpecs.
-func extractFeatureInfoList(filePath string, v ast.Expr, aliasMap map[string]string, variables map[string]ast.Expr, versioned bool) ([]featureInfo, error) {
+func extractFeatureInfoList(fset *token.FileSet, filePath string, v ast.Expr, aliasMap map[string]string, variables map[string]ast.Expr, versioned bool, waivedLines map[int]bool) ([]featureInfo, error) {
 	keys := []string{}
 	features := []featureInfo{}
 	cl, ok := v.(*ast.CompositeLit)
@@ -356,6 +578,10 @@ func extractFeatureInfoList(filePath string, v ast.Expr, aliasMap map[string]str
 		if err != nil {
 			return features, err
 		}
+		// a waiver comment at the end of the entry's first line or on the line above it
+		// waives the default progression check.
+		line := fset.Position(kv.Pos()).Line
+		info.DefaultProgressionWaived = waivedLines[line] || waivedLines[line-1]
 		features = append(features, info)
 		keys = append(keys, info.FullName)
 	}
@@ -22,8 +22,10 @@ import (
 	"go/token"
 	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -978,6 +980,81 @@ func featureGates() map[featuregate.Feature]featuregate.VersionedSpecs {
 	}
 }
 
+func TestVerifyNoOrphanedSpecs(t *testing.T) {
+	tests := []struct {
+		name          string
+		goFileContent string
+		minVersion    string
+		expectErr     bool
+	}{
+		{
+			name: "no orphaned specs",
+			goFileContent: `
+package features
+
+import (
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/component-base/featuregate"
+)
+var defaultVersionedKubernetesFeatureGates = map[featuregate.Feature]featuregate.VersionedSpecs{
+	AppArmorFields: {
+		{Version: version.MustParse("1.30"), Default: true, PreRelease: featuregate.Beta},
+		{Version: version.MustParse("1.31"), Default: true, PreRelease: featuregate.GA},
+	},
+}
+`,
+			minVersion: "1.31",
+		},
+		{
+			name: "all specs before min version",
+			goFileContent: `
+package features
+
+import (
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/component-base/featuregate"
+)
+var defaultVersionedKubernetesFeatureGates = map[featuregate.Feature]featuregate.VersionedSpecs{
+	AppArmorFields: {
+		{Version: version.MustParse("1.27"), Default: true, PreRelease: featuregate.Beta},
+		{Version: version.MustParse("1.28"), Default: true, LockToDefault: true, PreRelease: featuregate.GA},
+	},
+	CPUCFSQuotaPeriod: {
+		{Version: version.MustParse("1.29"), Default: false, PreRelease: featuregate.Alpha},
+		{Version: version.MustParse("1.31"), Default: true, PreRelease: featuregate.Beta},
+	},
+}
+`,
+			minVersion: "1.31",
+			expectErr:  true,
+		},
+		{
+			name: "invalid min version",
+			goFileContent: `
+package features
+`,
+			minVersion: "latest",
+			expectErr:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			goFile := writeContentToTmpFile(t, "", "pkg/new_features.go", tc.goFileContent)
+			tmpDir := filepath.Dir(filepath.Dir(goFile.Name()))
+			err := verifyNoOrphanedSpecs(tmpDir, tc.minVersion)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
 func writeContentToTmpFile(t *testing.T, tmpDir, fileName, fileContent string) *os.File {
 	if tmpDir == "" {
 		p, err := os.MkdirTemp("", "k8s")
@@ -1087,3 +1164,186 @@ func TestParseFeatureSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyDefaultProgression(t *testing.T) {
+	tests := []struct {
+		name          string
+		goFileContent string
+		expectErr     bool
+	}{
+		{
+			name: "monotonic progression",
+			goFileContent: `
+package features
+
+import (
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/component-base/featuregate"
+)
+var defaultVersionedKubernetesFeatureGates = map[featuregate.Feature]featuregate.VersionedSpecs{
+	AppArmorFields: {
+		{Version: version.MustParse("1.30"), Default: false, PreRelease: featuregate.Alpha},
+		{Version: version.MustParse("1.31"), Default: true, PreRelease: featuregate.Beta},
+		{Version: version.MustParse("1.32"), Default: true, PreRelease: featuregate.GA},
+	},
+}
+`,
+		},
+		{
+			name: "default regression",
+			goFileContent: `
+package features
+
+import (
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/component-base/featuregate"
+)
+var defaultVersionedKubernetesFeatureGates = map[featuregate.Feature]featuregate.VersionedSpecs{
+	AppArmorFields: {
+		{Version: version.MustParse("1.30"), Default: true, PreRelease: featuregate.Beta},
+		{Version: version.MustParse("1.31"), Default: false, PreRelease: featuregate.Beta},
+	},
+}
+`,
+			expectErr: true,
+		},
+		{
+			name: "waived default regression",
+			goFileContent: `
+package features
+
+import (
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/component-base/featuregate"
+)
+var defaultVersionedKubernetesFeatureGates = map[featuregate.Feature]featuregate.VersionedSpecs{
+	// default-progression-waiver: rolled back after a regression in 1.31.
+	AppArmorFields: {
+		{Version: version.MustParse("1.30"), Default: true, PreRelease: featuregate.Beta},
+		{Version: version.MustParse("1.31"), Default: false, PreRelease: featuregate.Beta},
+	},
+}
+`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			goFile := writeContentToTmpFile(t, "", "pkg/new_features.go", tc.goFileContent)
+			tmpDir := filepath.Dir(filepath.Dir(goFile.Name()))
+			err := verifyDefaultProgression(tmpDir)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestVerifyNoFutureSpecs(t *testing.T) {
+	tests := []struct {
+		name          string
+		goFileContent string
+		binaryVersion string
+		expectErr     bool
+	}{
+		{
+			name: "no future specs",
+			goFileContent: `
+package features
+
+import (
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/component-base/featuregate"
+)
+var defaultVersionedKubernetesFeatureGates = map[featuregate.Feature]featuregate.VersionedSpecs{
+	AppArmorFields: {
+		{Version: version.MustParse("1.30"), Default: true, PreRelease: featuregate.Beta},
+		{Version: version.MustParse("1.31"), Default: true, PreRelease: featuregate.GA},
+	},
+}
+`,
+			binaryVersion: "1.31",
+		},
+		{
+			name: "future dated spec",
+			goFileContent: `
+package features
+
+import (
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/component-base/featuregate"
+)
+var defaultVersionedKubernetesFeatureGates = map[featuregate.Feature]featuregate.VersionedSpecs{
+	AppArmorFields: {
+		{Version: version.MustParse("1.30"), Default: true, PreRelease: featuregate.Beta},
+		{Version: version.MustParse("1.33"), Default: true, PreRelease: featuregate.GA},
+	},
+}
+`,
+			binaryVersion: "1.31",
+			expectErr:     true,
+		},
+		{
+			name: "invalid binary version",
+			goFileContent: `
+package features
+`,
+			binaryVersion: "latest",
+			expectErr:     true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			goFile := writeContentToTmpFile(t, "", "pkg/new_features.go", tc.goFileContent)
+			tmpDir := filepath.Dir(filepath.Dir(goFile.Name()))
+			err := verifyNoFutureSpecs(tmpDir, tc.binaryVersion)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestReadFileAtGitRef(t *testing.T) {
+	originalExecCommand := execCommand
+	defer func() { execCommand = originalExecCommand }()
+
+	var calledArgs []string
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		calledArgs = append([]string{name}, args...)
+		return exec.Command("echo", "- name: StubbedFeature")
+	}
+
+	content, err := readFileAtGitRef("/repo", "origin/master", "test_data/versioned_feature_list.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "StubbedFeature") {
+		t.Errorf("expected content from the git ref, got %q", string(content))
+	}
+	expectedArgs := []string{"git", "-C", "/repo", "show", "origin/master:test_data/versioned_feature_list.yaml"}
+	if !reflect.DeepEqual(expectedArgs, calledArgs) {
+		t.Errorf("expected git invocation %v, got %v", expectedArgs, calledArgs)
+	}
+
+	// a missing ref surfaces the git error.
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		return exec.Command("sh", "-c", "echo 'fatal: invalid object name' >&2; exit 128")
+	}
+	if _, err := readFileAtGitRef("/repo", "no-such-ref", "test_data/versioned_feature_list.yaml"); err == nil {
+		t.Fatal("expected an error for a missing ref, got nil")
+	} else if !strings.Contains(err.Error(), "invalid object name") {
+		t.Errorf("expected the git stderr in the error, got %v", err)
+	}
+}